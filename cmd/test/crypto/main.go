@@ -0,0 +1,100 @@
+package main
+
+import (
+	"boilerplate-go/internal/common/enum"
+	"boilerplate-go/internal/pkg/helper"
+	"boilerplate-go/internal/pkg/secureclient"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Reads a payload from stdin and encrypts, decrypts or signs it the way EncryptMiddleware expects.
+//
+//	echo '{"username":"username"}' | go run main.go -mode encrypt
+//	echo 'gcm:v1:...' | go run main.go -mode decrypt
+//	echo '{"data":"gcm:v1:..."}' | go run main.go -mode sign -method POST -path /api/auth/login-encrypt
+//	go run main.go -mode sign-legacy < /dev/null
+func main() {
+	mode := flag.String("mode", "encrypt", "encrypt | decrypt | sign | sign-legacy")
+	method := flag.String("method", "POST", "HTTP method to sign")
+	path := flag.String("path", "/", "request path to sign")
+	query := flag.String("query", "", "raw query string to sign")
+	tenant := flag.String("tenant", "", "x-tenant header value")
+	legacy := flag.Bool("legacy", false, "encrypt with the legacy AES-CBC format")
+	envFile := flag.String("env", ".env", "env file to load keys from")
+	flag.Parse()
+
+	if err := godotenv.Load(*envFile); err != nil {
+		fmt.Fprintln(os.Stderr, "warning: failed to load env file:", err)
+	}
+
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		exit(err)
+	}
+	payload := strings.TrimRight(string(input), "\r\n")
+
+	client := secureclient.New(&secureclient.Config{Tenant: *tenant, Legacy: *legacy})
+
+	switch *mode {
+	case "encrypt":
+		envelope, err := client.EncryptBody(rawJSON(payload))
+		if err != nil {
+			exit(err)
+		}
+		out, err := helper.JSONToString(envelope)
+		if err != nil {
+			exit(err)
+		}
+		fmt.Println(out)
+	case "decrypt":
+		plaintext, err := helper.DecryptPayload(payload)
+		if err != nil {
+			exit(err)
+		}
+		fmt.Println(plaintext)
+	case "sign":
+		values, err := url.ParseQuery(*query)
+		if err != nil {
+			exit(err)
+		}
+		headers, err := client.SignHeaders(enum.HTTPMethodEnum(strings.ToUpper(*method)), *path, values, []byte(payload))
+		if err != nil {
+			exit(err)
+		}
+		printHeaders(headers)
+	case "sign-legacy":
+		headers, err := secureclient.LegacyHeaders(time.Now().Unix())
+		if err != nil {
+			exit(err)
+		}
+		printHeaders(headers)
+	default:
+		exit(fmt.Errorf("unknown mode %q", *mode))
+	}
+}
+
+type rawJSON string
+
+func (r rawJSON) MarshalJSON() ([]byte, error) {
+	return []byte(r), nil
+}
+
+func printHeaders(headers http.Header) {
+	for key, values := range headers {
+		fmt.Printf("%s: %s\n", key, strings.Join(values, ", "))
+	}
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
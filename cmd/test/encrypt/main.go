@@ -5,8 +5,10 @@ import (
 	"boilerplate-go/internal/pkg/helper"
 	"boilerplate-go/internal/pkg/jwt"
 	"boilerplate-go/internal/pkg/logger"
+	"boilerplate-go/internal/pkg/metrics"
 	"boilerplate-go/internal/pkg/middleware"
 	"boilerplate-go/internal/pkg/redis"
+	"boilerplate-go/internal/pkg/telemetry"
 	"context"
 	"time"
 
@@ -30,29 +32,47 @@ type Check2 struct {
 }
 
 func main() {
-	logger.Setup()
 	err := godotenv.Load()
 	if err != nil {
 		panic("Error reading .env file")
 	}
+	logger.Setup()
 	ctx := context.Background()
 
+	shutdownTelemetry, err := telemetry.Setup(ctx, telemetry.DefaultConfig())
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		_ = shutdownTelemetry(context.Background())
+	}()
+
 	rds, err := setupRedis(ctx)
 	if err != nil {
 		logger.Error.Println("Error connecting to redis")
 		panic(err)
 	}
-	r := gin.Default()
-	r.Use(middleware.CorsMiddleware())
+	r := gin.New()
+	r.Use(middleware.AccessLogMiddleware(middleware.DefaultAccessLogOptions()))
+	r.Use(middleware.TracingMiddleware(telemetry.DefaultConfig().ServiceName, "/metrics"))
+	r.Use(middleware.MetricsMiddleware("/metrics"))
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	r.Use(middleware.CorsMiddleware(middleware.DefaultCorsOptions()))
 	r.Use(middleware.RequestInit())
 	r.Use(middleware.ResponseInit())
+	r.Use(middleware.Recovery(nil))
 
 	jwtOpts := jwt.DefaultOptions("bismillah")
 	jwtOpts.TokenExpiredTime = 60 * time.Second
-	jwtAuth := jwt.New(jwtOpts)
+	jwtAuth := jwt.New(rds, jwtOpts)
 
 	r.POST("/encrypt", encryptHandler)
 
+	r.Use(middleware.RateLimitMiddleware(
+		middleware.NewRedisRateLimiter(rds),
+		middleware.DefaultRateLimitOptions(60, time.Minute),
+	))
+
 	r.Use(middleware.EncryptMiddleware(rds))
 
 	r.POST("/post", postHandler)
@@ -70,6 +90,8 @@ func setupRedis(ctx context.Context) (redis.IRedis, error) {
 		Host:     "localhost",
 		Port:     6379,
 		PoolSize: 10,
+
+		HealthCheckInterval: 10 * time.Second,
 	})
 }
 
@@ -122,7 +144,7 @@ func encryptHandler(c *gin.Context) {
 		return
 	}
 
-	resp, err := helper.EncryptAESCBC(strData)
+	resp, err := helper.EncryptPayload(strData)
 	if err != nil {
 		c.JSON(500, gin.H{
 			"message": "Error encrypting data",
@@ -130,7 +152,7 @@ func encryptHandler(c *gin.Context) {
 		return
 	}
 
-	resp2, err := helper.DecryptAESCBC(resp)
+	resp2, err := helper.DecryptPayload(resp)
 	if err != nil {
 		c.JSON(500, gin.H{
 			"message": "Error decrypting data",
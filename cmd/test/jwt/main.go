@@ -24,16 +24,20 @@ func main() {
 		Port:     6379,
 		Password: "",
 		PoolSize: 10,
+
+		HealthCheckInterval: 10 * time.Second,
 	})
 	if err != nil {
 		logger.Error.Println("Error connecting to redis")
 		panic(err)
 	}
 	jwtAuth := jwt.New(rds, jwtOpts)
-	r := gin.Default()
-	r.Use(middleware.CorsMiddleware())
+	r := gin.New()
+	r.Use(middleware.AccessLogMiddleware(middleware.DefaultAccessLogOptions()))
+	r.Use(middleware.CorsMiddleware(middleware.DefaultCorsOptions()))
 	r.Use(middleware.RequestInit())
 	r.Use(middleware.ResponseInit())
+	r.Use(middleware.Recovery(nil))
 
 	handler := auth.NewHandler(jwtAuth)
 	handler.NewRoutes(r.Group("/api"), jwtAuth)
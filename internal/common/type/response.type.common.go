@@ -18,8 +18,27 @@ type ResponseAPIDebug struct {
 	RuntimeMs int64     `json:"runtimeMs"` // Runtime in milliseconds for better precision
 }
 
+type ResponseAPIError struct {
+	Code    string `json:"code"`
+	Details any    `json:"details,omitempty"`
+}
+
 type ResponseAPI struct {
 	Data    any               `json:"data"`
 	Message string            `json:"message"`
+	Error   *ResponseAPIError `json:"error,omitempty"`
 	Debug   *ResponseAPIDebug `json:"debug,omitempty"`
 }
+
+// ProblemDetails is the RFC 7807 body sent with application/problem+json responses. Code,
+// RequestID and Errors are extension members.
+type ProblemDetails struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	Errors    any    `json:"errors,omitempty"`
+}
@@ -30,9 +30,9 @@ func (h *Handler) Login(c *gin.Context) {
 		"password": "password",
 	}
 
-	sample["token"], sample["expired"] = h.auth.GenerateToken(sample)
+	sample["token"], sample["expired"] = h.auth.GenerateToken(c.Request.Context(), sample)
 
-	token, err := h.auth.ValidateToken(sample["token"].(string))
+	token, err := h.auth.ValidateToken(c.Request.Context(), sample["token"].(string))
 	if err != nil {
 		c.JSON(400, gin.H{
 			"message": "error",
@@ -47,9 +47,9 @@ func (h *Handler) Login(c *gin.Context) {
 
 func (h *Handler) LoginEncrypt(c *gin.Context) {
 	sample := c.MustGet("body").(map[string]interface{})
-	sample["token"], sample["expired"] = h.auth.GenerateToken(sample)
+	sample["token"], sample["expired"] = h.auth.GenerateToken(c.Request.Context(), sample)
 
-	token, err := h.auth.ValidateToken(sample["token"].(string))
+	token, err := h.auth.ValidateToken(c.Request.Context(), sample["token"].(string))
 	if err != nil {
 		c.JSON(400, gin.H{
 			"message": "error",
@@ -77,7 +77,7 @@ func (h *Handler) SampleDataLoginEncrypt(c *gin.Context) {
 		return
 	}
 
-	resp, err := helper.EncryptAESCBC(strData)
+	resp, err := helper.EncryptPayload(strData)
 	if err != nil {
 		c.JSON(500, gin.H{
 			"message": "Error encrypting data",
@@ -0,0 +1,169 @@
+package apperror
+
+import (
+	database "boilerplate-go/internal/pkg/db"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	CodeBadRequest   = "BAD_REQUEST"
+	CodeValidation   = "VALIDATION_FAILED"
+	CodeUnauthorized = "UNAUTHORIZED"
+	CodeForbidden    = "FORBIDDEN"
+	CodeNotFound     = "NOT_FOUND"
+	CodeConflict     = "CONFLICT"
+	CodeRateLimited  = "RATE_LIMITED"
+	CodeInternal     = "INTERNAL"
+
+	CodeIdempotencyInProgress = "IDEMPOTENCY_IN_PROGRESS"
+	CodeIdempotencyMismatch   = "IDEMPOTENCY_KEY_REUSED"
+)
+
+// Error is an application error with a stable machine readable code, the HTTP status it maps
+// to, a client safe message and optional details. The wrapped cause is never sent to clients.
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+	Details any
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns a copy of e with err as its cause.
+func (e *Error) Wrap(err error) *Error {
+	clone := *e
+	clone.Err = err
+	return &clone
+}
+
+// WithDetails returns a copy of e carrying details for the client.
+func (e *Error) WithDetails(details any) *Error {
+	clone := *e
+	clone.Details = details
+	return &clone
+}
+
+func New(code string, status int, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+func BadRequest(message string) *Error {
+	return New(CodeBadRequest, http.StatusBadRequest, message)
+}
+
+func Validation(message string) *Error {
+	return New(CodeValidation, http.StatusUnprocessableEntity, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(CodeUnauthorized, http.StatusUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(CodeForbidden, http.StatusForbidden, message)
+}
+
+func NotFound(message string) *Error {
+	return New(CodeNotFound, http.StatusNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(CodeConflict, http.StatusConflict, message)
+}
+
+func RateLimited(message string) *Error {
+	return New(CodeRateLimited, http.StatusTooManyRequests, message)
+}
+
+func Internal(message string) *Error {
+	return New(CodeInternal, http.StatusInternalServerError, message)
+}
+
+// DetailedError is implemented by validation errors that carry their own field level details,
+// such as validation.ValidationErrors.
+type DetailedError interface {
+	error
+	ValidationDetails() any
+}
+
+type FieldError struct {
+	Field string `json:"field"`
+	Tag   string `json:"tag"`
+	Param string `json:"param,omitempty"`
+}
+
+// From converts any error into an *Error. Application errors are returned as is, record not
+// found and duplicate key errors from the database package and validation errors are mapped to
+// their kinds, and anything else becomes an internal error.
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var detailed DetailedError
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &detailed):
+		return Validation("Validation Failed").WithDetails(detailed.ValidationDetails()).Wrap(err)
+	case database.IsNotFound(err):
+		return NotFound("Not Found").Wrap(err)
+	case database.IsDuplicateKey(err):
+		return Conflict("Conflict").Wrap(err)
+	case errors.As(err, &validationErrors):
+		details := make([]FieldError, 0, len(validationErrors))
+		for _, fieldErr := range validationErrors {
+			details = append(details, FieldError{
+				Field: fieldErr.Namespace(),
+				Tag:   fieldErr.Tag(),
+				Param: fieldErr.Param(),
+			})
+		}
+		return Validation("Validation Failed").WithDetails(details).Wrap(err)
+	default:
+		return Internal("Internal Server Error").Wrap(err)
+	}
+}
+
+// CodeForStatus returns the error code used for responses that carry a plain error.
+func CodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnprocessableEntity:
+		return CodeValidation
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	default:
+		if status >= http.StatusInternalServerError {
+			return CodeInternal
+		}
+		return CodeBadRequest
+	}
+}
@@ -0,0 +1,293 @@
+package cache
+
+import (
+	database "boilerplate-go/internal/pkg/db"
+	"boilerplate-go/internal/pkg/logger"
+	"boilerplate-go/internal/pkg/redis"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// negativeMarker is stored in place of a value for negatively cached keys. It is not valid JSON,
+// so it cannot collide with an encoded value.
+var negativeMarker = []byte("\x00cache:not-found")
+
+func DefaultOptions() *Options {
+	return &Options{
+		Prefix:      "cache:",
+		DefaultTTL:  5 * time.Minute,
+		Jitter:      0.1,
+		NegativeTTL: 30 * time.Second,
+		IsNotFound: func(err error) bool {
+			return errors.Is(err, ErrNotFound) || database.IsNotFound(err)
+		},
+		L1Size:       0,
+		L1TTL:        10 * time.Second,
+		TagPruneSize: 1000,
+	}
+}
+
+func New(rds redis.IRedis, opts *Options) *Cache {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	c := &Cache{redis: rds, opts: opts}
+	if opts.L1Size > 0 {
+		c.l1 = newLRU(opts.L1Size, opts.L1TTL)
+	}
+	return c
+}
+
+// GetOrLoad returns the cached value for key, calling loader on a miss. Concurrent misses for
+// the same key on this instance share a single loader call. The loader runs without the
+// caller's cancellation so one cancelled request does not fail the others waiting on it.
+func GetOrLoad[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, loader func(ctx context.Context) (T, error), tags ...string) (T, error) {
+	var value T
+
+	data, err := c.load(ctx, key, ttl, func(ctx context.Context) ([]byte, error) {
+		loaded, err := loader(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(loaded)
+	}, tags)
+	if err != nil {
+		return value, err
+	}
+
+	if err = json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("failed to decode cache key %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// Get returns the cached value for key. ok is false on a miss and for negatively cached keys.
+func Get[T any](ctx context.Context, c *Cache, key string) (value T, ok bool, err error) {
+	data, found := c.lookup(ctx, c.opts.Prefix+key)
+	if !found || bytes.Equal(data, negativeMarker) {
+		return value, false, nil
+	}
+
+	if err = json.Unmarshal(data, &value); err != nil {
+		return value, false, fmt.Errorf("failed to decode cache key %s: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Set writes value to the cache, e.g. after an update, replacing any negative entry.
+func (c *Cache) Set(ctx context.Context, key string, value any, ttl time.Duration, tags ...string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache key %s: %w", key, err)
+	}
+	if ttl <= 0 {
+		ttl = c.opts.DefaultTTL
+	}
+	return c.store(ctx, c.opts.Prefix+key, data, c.jitter(ttl), tags)
+}
+
+// Invalidate removes keys from redis and from the local L1.
+func (c *Cache) Invalidate(ctx context.Context, keys ...string) error {
+	fullKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		fullKeys = append(fullKeys, c.opts.Prefix+key)
+	}
+
+	if c.l1 != nil {
+		c.l1.remove(fullKeys...)
+	}
+	if err := c.del(ctx, fullKeys...); err != nil {
+		return fmt.Errorf("failed to invalidate cache keys: %w", err)
+	}
+	return nil
+}
+
+// InvalidateTags removes every entry stored with one of tags.
+func (c *Cache) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		tagKey := c.tagKey(tag)
+		keys, err := c.redis.SMembers(ctx, tagKey)
+		if err != nil {
+			return fmt.Errorf("failed to read cache tag %s: %w", tag, err)
+		}
+
+		if c.l1 != nil {
+			c.l1.remove(keys...)
+		}
+		if err = c.del(ctx, append(keys, tagKey)...); err != nil {
+			return fmt.Errorf("failed to invalidate cache tag %s: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// del deletes every key with its own DEL, as one multi-key DEL fails with CROSSSLOT on a cluster
+// when the keys hash to different slots.
+func (c *Cache) del(ctx context.Context, keys ...string) error {
+	return c.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(key)
+		}
+		return nil
+	})
+}
+
+func (c *Cache) shouldPrune(added, size int64) bool {
+	return c.opts.TagPruneSize > 0 && added > 0 && size%c.opts.TagPruneSize == 0
+}
+
+// pruneTag removes the members of a tag set whose entries expired. Without it the set of a tag
+// that is written often but rarely invalidated keeps growing, as every write extends its TTL.
+func (c *Cache) pruneTag(ctx context.Context, tagKey string) error {
+	keys, err := c.redis.SMembers(ctx, tagKey)
+	if err != nil {
+		return err
+	}
+
+	exists := make([]*redis.IntCmd, len(keys))
+	err = c.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			exists[i] = pipe.Exists(key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	expired := make([]interface{}, 0, len(keys))
+	for i, key := range keys {
+		if exists[i].Val() == 0 {
+			expired = append(expired, key)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	_, err = c.redis.SRem(ctx, tagKey, expired...)
+	return err
+}
+
+func (c *Cache) load(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) ([]byte, error), tags []string) ([]byte, error) {
+	if ttl <= 0 {
+		ttl = c.opts.DefaultTTL
+	}
+	fullKey := c.opts.Prefix + key
+
+	if data, ok := c.lookup(ctx, fullKey); ok {
+		return decode(data)
+	}
+
+	result, err, _ := c.group.Do(fullKey, func() (interface{}, error) {
+		// Another instance may have filled the key while this call waited for the group.
+		if data, ok := c.lookup(ctx, fullKey); ok {
+			return data, nil
+		}
+
+		loadCtx := context.WithoutCancel(ctx)
+		data, err := loader(loadCtx)
+		if err != nil {
+			if c.opts.NegativeTTL > 0 && c.opts.IsNotFound != nil && c.opts.IsNotFound(err) {
+				_ = c.store(loadCtx, fullKey, negativeMarker, c.opts.NegativeTTL, tags)
+				return negativeMarker, nil
+			}
+			return nil, err
+		}
+
+		_ = c.store(loadCtx, fullKey, data, c.jitter(ttl), tags)
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decode(result.([]byte))
+}
+
+func decode(data []byte) ([]byte, error) {
+	if bytes.Equal(data, negativeMarker) {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+// lookup reads the L1 then redis. Redis failures are logged and treated as a miss so the cache
+// never takes the application down with it.
+func (c *Cache) lookup(ctx context.Context, fullKey string) ([]byte, bool) {
+	if c.l1 != nil {
+		if data, ok := c.l1.get(fullKey); ok {
+			return data, true
+		}
+	}
+
+	value, err := c.redis.Get(ctx, fullKey)
+	if err != nil {
+		if !redis.IsNil(err) {
+			logger.FromContext(ctx).Warn("cache read failed", "key", fullKey, "error", err)
+		}
+		return nil, false
+	}
+
+	data := []byte(value)
+	if c.l1 != nil {
+		c.l1.add(fullKey, data, 0)
+	}
+	return data, true
+}
+
+func (c *Cache) store(ctx context.Context, fullKey string, data []byte, ttl time.Duration, tags []string) error {
+	if c.l1 != nil {
+		c.l1.add(fullKey, data, ttl)
+	}
+
+	added := make([]*redis.IntCmd, len(tags))
+	sizes := make([]*redis.IntCmd, len(tags))
+	err := c.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(fullKey, data, ttl)
+		for i, tag := range tags {
+			added[i] = pipe.SAdd(c.tagKey(tag), fullKey)
+			sizes[i] = pipe.SCard(c.tagKey(tag))
+		}
+		return nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("cache write failed", "key", fullKey, "error", err)
+		return fmt.Errorf("failed to write cache key %s: %w", fullKey, err)
+	}
+
+	// A tag set must outlive every entry it points to.
+	for i, tag := range tags {
+		tagKey := c.tagKey(tag)
+		current, err := c.redis.TTL(ctx, tagKey)
+		if err == nil && (current == -1 || (current >= 0 && current < ttl)) {
+			_, err = c.redis.Expire(ctx, tagKey, ttl)
+		}
+		if err == nil && c.shouldPrune(added[i].Val(), sizes[i].Val()) {
+			err = c.pruneTag(ctx, tagKey)
+		}
+		if err != nil {
+			logger.FromContext(ctx).Warn("cache tag update failed", "tag", tag, "error", err)
+		}
+	}
+	return nil
+}
+
+func (c *Cache) tagKey(tag string) string {
+	return c.opts.Prefix + "tag:" + tag
+}
+
+func (c *Cache) jitter(ttl time.Duration) time.Duration {
+	if c.opts.Jitter <= 0 {
+		return ttl
+	}
+	spread := time.Duration(float64(ttl) * c.opts.Jitter)
+	if spread <= 0 {
+		return ttl
+	}
+	return ttl - spread + rand.N(2*spread)
+}
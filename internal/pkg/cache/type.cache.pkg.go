@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"boilerplate-go/internal/pkg/redis"
+	"errors"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNotFound is returned for keys whose loader reported a missing value, including while that
+// result is negatively cached.
+var ErrNotFound = errors.New("cache: not found")
+
+type Options struct {
+	Prefix     string
+	DefaultTTL time.Duration
+	// Jitter spreads expirations over ttl ± ttl*Jitter so entries loaded together do not expire
+	// together.
+	Jitter float64
+	// NegativeTTL is how long a not found result is cached; 0 disables negative caching.
+	NegativeTTL time.Duration
+	// IsNotFound decides which loader errors are negatively cached.
+	IsNotFound func(err error) bool
+	// L1Size enables an in-process LRU in front of redis. L1 entries are not invalidated on other
+	// instances, so L1TTL should stay short.
+	L1Size int
+	L1TTL  time.Duration
+	// TagPruneSize prunes a tag set each time it grows by that many members: members whose
+	// entries expired are removed. 0 disables pruning.
+	TagPruneSize int64
+}
+
+type Cache struct {
+	redis redis.IRedis
+	opts  *Options
+	group singleflight.Group
+	l1    *lru
+}
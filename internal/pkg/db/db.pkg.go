@@ -1,12 +1,14 @@
 package database
 
 import (
+	"boilerplate-go/internal/pkg/metrics"
 	"fmt"
 	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/plugin/opentelemetry/tracing"
 )
 
 type Config struct {
@@ -16,6 +18,8 @@ type Config struct {
 	Password string
 	Database string
 	SSLMode  string
+	Tracing  bool
+	Metrics  bool
 }
 
 type Database struct {
@@ -51,6 +55,17 @@ func Setup(cfg *Config) (*Database, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	if cfg.Tracing {
+		err = db.Use(tracing.NewPlugin(
+			tracing.WithDBName(cfg.Database),
+			tracing.WithoutQueryVariables(),
+			tracing.WithoutMetrics(),
+		))
+		if err != nil {
+			return nil, fmt.Errorf("failed to enable database tracing: %w", err)
+		}
+	}
+
 	// Set connection pool settings
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -62,6 +77,12 @@ func Setup(cfg *Config) (*Database, error) {
 	sqlDB.SetMaxOpenConns(20)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
+	if cfg.Metrics {
+		if err := metrics.RegisterDBStats(cfg.Database, sqlDB); err != nil {
+			return nil, fmt.Errorf("failed to register database metrics: %w", err)
+		}
+	}
+
 	return &Database{db, crypto}, nil
 }
 
@@ -0,0 +1,45 @@
+package helper
+
+import (
+	"boilerplate-go/internal/pkg/logger"
+	"context"
+)
+
+const (
+	RequestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+type requestIDKey struct{}
+
+// ContextWithRequestID stores the request id in ctx and adds it to the context logger.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	return logger.With(ctx, "requestId", requestID)
+}
+
+// RequestIDFromContext returns the request id stored by ContextWithRequestID, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// IsValidRequestID accepts ids of up to 128 characters made of letters, digits and "-_.:".
+// Anything else is replaced so client supplied ids cannot inject into logs or headers.
+func IsValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -61,6 +61,13 @@ func DecryptDips(text string) (string, error) {
 		return "", fmt.Errorf("failed to decode IV or encrypted text: %w", err)
 	}
 
+	if len(iv) != aes.BlockSize {
+		return "", errors.New("invalid IV length")
+	}
+	if len(encryptedText) == 0 || len(encryptedText)%aes.BlockSize != 0 {
+		return "", errors.New("ciphertext is not a multiple of the block size")
+	}
+
 	key := []byte(os.Getenv("DIPS_PASSWORD"))
 	if block, err := aes.NewCipher(key); err != nil {
 		return "", fmt.Errorf("failed to create cipher block: %w", err)
@@ -68,8 +75,11 @@ func DecryptDips(text string) (string, error) {
 		decrypted := make([]byte, len(encryptedText))
 		cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, encryptedText)
 
-		unpadding := int(decrypted[len(decrypted)-1])
-		return string(decrypted[:len(decrypted)-unpadding]), nil
+		decrypted = unpad(decrypted)
+		if decrypted == nil {
+			return "", errors.New("padding error")
+		}
+		return string(decrypted), nil
 	}
 }
 
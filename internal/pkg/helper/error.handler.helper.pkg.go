@@ -1,14 +1,17 @@
 package helper
 
 import (
+	"boilerplate-go/internal/pkg/apperror"
 	"boilerplate-go/internal/pkg/logger"
 )
 
+// HandleAppError logs err and, when fatal, returns it as an *apperror.Error so handlers can pass
+// it straight to ParseError.
 func HandleAppError(err error, function, step string, fatal bool) error {
 	if err != nil {
 		if fatal {
 			logger.Error.Println("Fatal error in function: ", function, "Step: ", step, "Details: ", err)
-			return err
+			return apperror.From(err)
 		}
 		logger.Error.Println("Error in function: ", function, "Step: ", step, "Details: ", err)
 	}
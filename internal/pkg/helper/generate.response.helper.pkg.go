@@ -2,10 +2,16 @@ package helper
 
 import (
 	_type "boilerplate-go/internal/common/type"
+	"boilerplate-go/internal/pkg/apperror"
+	"errors"
 	"net/http"
 )
 
 func ParseResponse(r *_type.Response) *_type.Response {
+	var appErr *apperror.Error
+	if r.Code == 0 && errors.As(r.Error, &appErr) {
+		r.Code = appErr.Status
+	}
 	if r.Code < 200 || r.Code >= 599 {
 		r.Code = http.StatusInternalServerError
 	}
@@ -15,6 +21,16 @@ func ParseResponse(r *_type.Response) *_type.Response {
 	return r
 }
 
+// ParseError builds an error response from err, mapping it to an application error first.
+func ParseError(err error) *_type.Response {
+	appErr := apperror.From(err)
+	return ParseResponse(&_type.Response{
+		Code:    appErr.Status,
+		Message: appErr.Message,
+		Error:   appErr,
+	})
+}
+
 func generateMessage(r *_type.Response) {
 	switch {
 	case r.Code == http.StatusOK:
@@ -29,8 +45,14 @@ func generateMessage(r *_type.Response) {
 		r.Message = "Forbidden"
 	case r.Code == http.StatusNotFound:
 		r.Message = "Not Found"
+	case r.Code == http.StatusConflict:
+		r.Message = "Conflict"
+	case r.Code == http.StatusUnprocessableEntity:
+		r.Message = "Unprocessable Entity"
 	case r.Code == http.StatusMethodNotAllowed:
 		r.Message = "Method Not Allowed"
+	case r.Code == http.StatusTooManyRequests:
+		r.Message = "Too Many Requests"
 	case r.Code == http.StatusInternalServerError:
 		r.Message = "Internal Server Error"
 	case r.Code == http.StatusServiceUnavailable:
@@ -16,6 +16,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type HTTPAPIResponse struct {
@@ -100,14 +102,22 @@ func prepareRequest(payload *HTTPRequestPayload, body io.Reader, config *HTTPReq
 		req.Header[key] = append(req.Header[key], values...)
 	}
 
+	if requestID := RequestIDFromContext(config.Ctx); requestID != "" && req.Header.Get(RequestIDHeader) == "" {
+		req.Header.Set(RequestIDHeader, requestID)
+	}
+
 	if config.Auth != nil {
 		req.SetBasicAuth(config.Auth.Username, config.Auth.Password)
 	}
 
-	client := &http.Client{Timeout: 1 * time.Minute}
-
+	var transport http.RoundTripper = http.DefaultTransport
 	if config.HTTPAgent != nil {
-		client.Transport = config.HTTPAgent
+		transport = config.HTTPAgent
+	}
+
+	client := &http.Client{
+		Timeout:   1 * time.Minute,
+		Transport: otelhttp.NewTransport(transport),
 	}
 
 	return req, client, nil
@@ -0,0 +1,204 @@
+package helper
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// PayloadSchemeGCM prefixes payloads produced by EncryptPayload. The full format is
+// "gcm:<keyID>:<base64url(nonce||ciphertext||tag)>". Legacy AES-CBC payloads are plain
+// standard base64 and never contain ':', so both formats can be told apart.
+const PayloadSchemeGCM = "gcm"
+
+var (
+	ErrPayloadKeyNotFound = errors.New("payload key not found")
+	ErrPayloadFormat      = errors.New("invalid payload format")
+)
+
+// envKeyring caches the keyring parsed from the environment by EncryptPayload and DecryptPayload.
+var (
+	envKeyringMu sync.Mutex
+	envKeyring   *PayloadKeyring
+)
+
+// PayloadKeyring holds every key allowed to decrypt payloads; only the current key encrypts.
+type PayloadKeyring struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewPayloadKeyring builds a keyring from raw 32 byte AES-256 keys indexed by key id.
+func NewPayloadKeyring(current string, keys map[string][]byte) (*PayloadKeyring, error) {
+	kr := &PayloadKeyring{
+		current: current,
+		keys:    make(map[string]cipher.AEAD, len(keys)),
+	}
+
+	for id, key := range keys {
+		if err := kr.AddKey(id, key); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := kr.keys[current]; !ok {
+		return nil, fmt.Errorf("current key %q: %w", current, ErrPayloadKeyNotFound)
+	}
+
+	return kr, nil
+}
+
+// PayloadKeyringFromEnv loads the keyring from PAYLOAD_KEYS ("id:base64key,id:base64key")
+// and PAYLOAD_KEY_CURRENT. Keys must decode to 32 bytes.
+func PayloadKeyringFromEnv() (*PayloadKeyring, error) {
+	rawKeys := GetEnv("PAYLOAD_KEYS")
+	if rawKeys == "" {
+		return nil, errors.New("PAYLOAD_KEYS environment variable is not set")
+	}
+
+	keys := make(map[string][]byte)
+	for _, entry := range strings.Split(rawKeys, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.New("PAYLOAD_KEYS entries must use the id:base64key format")
+		}
+
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode payload key %q: %w", parts[0], err)
+		}
+		keys[parts[0]] = key
+	}
+
+	return NewPayloadKeyring(GetEnv("PAYLOAD_KEY_CURRENT"), keys)
+}
+
+// AddKey registers a decryption key. It does not change the current encryption key.
+func (kr *PayloadKeyring) AddKey(id string, key []byte) error {
+	if id == "" || strings.Contains(id, ":") {
+		return fmt.Errorf("invalid payload key id %q", id)
+	}
+	if len(key) != 32 {
+		return fmt.Errorf("payload key %q must be 32 bytes", id)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	kr.keys[id] = gcm
+	return nil
+}
+
+// CurrentKeyID returns the id of the key used by Encrypt.
+func (kr *PayloadKeyring) CurrentKeyID() string {
+	return kr.current
+}
+
+// Encrypt seals val with the current key and a random nonce.
+func (kr *PayloadKeyring) Encrypt(val string) (string, error) {
+	gcm, ok := kr.keys[kr.current]
+	if !ok {
+		return "", fmt.Errorf("current key %q: %w", kr.current, ErrPayloadKeyNotFound)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(val), []byte(kr.current))
+	return PayloadSchemeGCM + ":" + kr.current + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a payload produced by Encrypt with whichever key id it is prefixed with.
+func (kr *PayloadKeyring) Decrypt(payload string) (string, error) {
+	parts := strings.SplitN(payload, ":", 3)
+	if len(parts) != 3 || parts[0] != PayloadSchemeGCM || parts[1] == "" || parts[2] == "" {
+		return "", ErrPayloadFormat
+	}
+
+	gcm, ok := kr.keys[parts[1]]
+	if !ok {
+		return "", fmt.Errorf("key %q: %w", parts[1], ErrPayloadKeyNotFound)
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrPayloadFormat
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(sealed) < nonceSize+gcm.Overhead() {
+		return "", ErrPayloadFormat
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(parts[1]))
+	if err != nil {
+		return "", errors.New("payload authentication failed")
+	}
+
+	return string(plaintext), nil
+}
+
+// IsGCMPayload reports whether payload uses the authenticated format instead of legacy AES-CBC.
+func IsGCMPayload(payload string) bool {
+	return strings.HasPrefix(payload, PayloadSchemeGCM+":")
+}
+
+// EncryptPayload encrypts val with the keyring configured in the environment. The keyring is
+// parsed on first successful use, so key changes take effect on restart.
+func EncryptPayload(val string) (string, error) {
+	kr, err := payloadKeyringFromEnvCached()
+	if err != nil {
+		return "", err
+	}
+	return kr.Encrypt(val)
+}
+
+// DecryptPayload decrypts both payload formats. Legacy AES-CBC payloads are accepted
+// unless PAYLOAD_LEGACY is set to "0".
+func DecryptPayload(payload string) (string, error) {
+	if !IsGCMPayload(payload) {
+		if GetEnv("PAYLOAD_LEGACY") == "0" {
+			return "", errors.New("legacy payload format is disabled")
+		}
+		return DecryptAESCBC(payload)
+	}
+
+	kr, err := payloadKeyringFromEnvCached()
+	if err != nil {
+		return "", err
+	}
+	return kr.Decrypt(payload)
+}
+
+// payloadKeyringFromEnvCached parses the environment keyring once. Errors are not cached, so a
+// keyring configured after the first call is still picked up.
+func payloadKeyringFromEnvCached() (*PayloadKeyring, error) {
+	envKeyringMu.Lock()
+	defer envKeyringMu.Unlock()
+
+	if envKeyring != nil {
+		return envKeyring, nil
+	}
+
+	kr, err := PayloadKeyringFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	envKeyring = kr
+	return kr, nil
+}
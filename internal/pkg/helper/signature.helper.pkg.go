@@ -0,0 +1,46 @@
+package helper
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	HeaderTime      = "x-time"
+	HeaderNonce     = "x-nonce"
+	HeaderSignature = "x-signature"
+)
+
+// CanonicalRequest builds the string covered by a request signature:
+//
+//	METHOD \n PATH \n SORTED_QUERY \n hex(sha256(body)) \n TIMESTAMP \n NONCE
+func CanonicalRequest(method, path string, query url.Values, body []byte, timestamp int64, nonce string) string {
+	bodyHash := sha256.Sum256(body)
+
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		query.Encode(),
+		hex.EncodeToString(bodyHash[:]),
+		strconv.FormatInt(timestamp, 10),
+		nonce,
+	}, "\n")
+}
+
+// SignRequest returns the hex HMAC-SHA256 of the canonical request keyed with ENCRYPT_KEY.
+func SignRequest(method, path string, query url.Values, body []byte, timestamp int64, nonce string) (string, error) {
+	return HMACSHA256(CanonicalRequest(method, path, query, body, timestamp, nonce))
+}
+
+// VerifyRequestSignature recomputes the signature and compares it in constant time.
+func VerifyRequestSignature(signature, method, path string, query url.Values, body []byte, timestamp int64, nonce string) (bool, error) {
+	computed, err := SignRequest(method, path, query, body, timestamp, nonce)
+	if err != nil {
+		return false, err
+	}
+	return hmac.Equal([]byte(computed), []byte(strings.ToLower(signature))), nil
+}
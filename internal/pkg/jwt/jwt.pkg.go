@@ -3,6 +3,7 @@ package jwt
 import (
 	"boilerplate-go/internal/pkg/helper"
 	"boilerplate-go/internal/pkg/redis"
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -24,8 +25,8 @@ type Auth struct {
 }
 
 type IJWTAuth interface {
-	GenerateToken(data map[string]interface{}) (string, *time.Time)
-	ValidateToken(jwtToken string) (map[string]interface{}, error)
+	GenerateToken(ctx context.Context, data map[string]interface{}) (string, *time.Time)
+	ValidateToken(ctx context.Context, jwtToken string) (map[string]interface{}, error)
 }
 
 // New Auth object
@@ -40,7 +41,7 @@ func New(rds redis.IRedis, opt *Options) IJWTAuth {
 }
 
 // GenerateToken generate jwt token
-func (a *Auth) GenerateToken(data map[string]interface{}) (string, *time.Time) {
+func (a *Auth) GenerateToken(ctx context.Context, data map[string]interface{}) (string, *time.Time) {
 	exp := time.Now().Add(a.TokenExpiredTime)
 	sessionID, err := helper.GenerateID()
 	if err != nil {
@@ -75,7 +76,7 @@ func (a *Auth) GenerateToken(data map[string]interface{}) (string, *time.Time) {
 		if id, ok := data["id"]; ok {
 			strID := fmt.Sprintf("%v", id)
 			if strID != "" {
-				err = a.Redis.Set(os.Getenv("APP_TENANT")+":"+strID, sessionID, a.TokenExpiredTime)
+				err = redis.SetJSON(ctx, a.Redis, os.Getenv("APP_TENANT")+":"+strID, sessionID, a.TokenExpiredTime)
 				if err != nil {
 					return "", nil
 				}
@@ -91,7 +92,7 @@ func (a *Auth) GenerateToken(data map[string]interface{}) (string, *time.Time) {
 }
 
 // ValidateToken validate jwt token
-func (a *Auth) ValidateToken(jwtToken string) (map[string]interface{}, error) {
+func (a *Auth) ValidateToken(ctx context.Context, jwtToken string) (map[string]interface{}, error) {
 	tokenData := jwt.MapClaims{}
 	token, err := jwt.ParseWithClaims(jwtToken, tokenData, func(token *jwt.Token) (interface{}, error) {
 		return []byte(a.TokenSecretKey), nil
@@ -109,14 +110,14 @@ func (a *Auth) ValidateToken(jwtToken string) (map[string]interface{}, error) {
 		if id, ok := tokenData["id"]; ok {
 			strID := fmt.Sprintf("%v", id)
 			if strID != "" {
-				sessionID, er := a.Redis.Get(os.Getenv("APP_TENANT") + ":" + strID)
+				sessionID, er := redis.GetJSON[string](ctx, a.Redis, os.Getenv("APP_TENANT")+":"+strID)
 				if er != nil {
 					return nil, jwt.ErrInvalidKey
 				}
 				if sessionID == "" {
 					return nil, jwt.ErrInvalidKey
 				}
-				if sessionID != fmt.Sprintf("\"%s\"", tokenData["session_id"]) {
+				if sessionID != fmt.Sprintf("%v", tokenData["session_id"]) {
 					return nil, jwt.ErrInvalidKey
 				}
 			} else {
@@ -1,22 +1,95 @@
 package logger
 
 import (
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"strings"
 )
 
+// Debug, Info, Warning, Error and HTTP are kept so existing Println/Printf call sites keep
+// working. They write through the structured handler, so level filtering and the output
+// format apply to them too. New code should use Log or FromContext.
 var (
 	Debug   *log.Logger
 	Info    *log.Logger
 	Warning *log.Logger
 	Error   *log.Logger
 	HTTP    *log.Logger
+
+	Log *slog.Logger
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+
+	ChannelHTTP = "http"
 )
 
+type Config struct {
+	Level      slog.Level
+	Format     string
+	Output     io.Writer
+	AddSource  bool
+	RedactKeys []string
+}
+
+// DefaultConfig reads LOG_LEVEL (debug, info, warn, error) and LOG_FORMAT (json, text).
+func DefaultConfig() *Config {
+	format := strings.ToLower(os.Getenv("LOG_FORMAT"))
+	if format != FormatText {
+		format = FormatJSON
+	}
+
+	return &Config{
+		Level:      ParseLevel(os.Getenv("LOG_LEVEL")),
+		Format:     format,
+		Output:     os.Stdout,
+		RedactKeys: DefaultRedactKeys,
+	}
+}
+
+func ParseLevel(value string) slog.Level {
+	switch strings.ToLower(value) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
 func Setup() {
-	HTTP = log.New(os.Stdout, "[HTTP]\t", log.Ldate|log.Ltime)
-	Info = log.New(os.Stdout, "[INFO]\t", log.Ldate|log.Ltime)
-	Warning = log.New(os.Stdout, "[WARNING]\t", log.Ldate|log.Ltime|log.Lshortfile)
-	Debug = log.New(os.Stdout, "[DEBUG]\t", log.Ldate|log.Ltime|log.Lshortfile)
-	Error = log.New(os.Stdout, "[ERROR]\t", log.Ldate|log.Ltime|log.Lshortfile)
+	SetupWithConfig(DefaultConfig())
+}
+
+func SetupWithConfig(cfg *Config) {
+	redactor := newRedactor(cfg.RedactKeys)
+	handlerOpts := &slog.HandlerOptions{
+		Level:       cfg.Level,
+		AddSource:   cfg.AddSource,
+		ReplaceAttr: redactor.replaceAttr,
+	}
+
+	var handler slog.Handler
+	if cfg.Format == FormatText {
+		handler = slog.NewTextHandler(cfg.Output, handlerOpts)
+	} else {
+		handler = slog.NewJSONHandler(cfg.Output, handlerOpts)
+	}
+
+	Log = slog.New(handler)
+	slog.SetDefault(Log)
+	defaultRedactor = redactor
+
+	HTTP = slog.NewLogLogger(handler.WithAttrs([]slog.Attr{slog.String("channel", ChannelHTTP)}), slog.LevelInfo)
+	Info = slog.NewLogLogger(handler, slog.LevelInfo)
+	Warning = slog.NewLogLogger(handler, slog.LevelWarn)
+	Debug = slog.NewLogLogger(handler, slog.LevelDebug)
+	Error = slog.NewLogLogger(handler, slog.LevelError)
 }
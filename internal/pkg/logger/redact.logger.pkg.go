@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"log/slog"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+var DefaultRedactKeys = []string{
+	"password", "passwd", "secret", "token", "access_token", "refresh_token",
+	"authorization", "cookie", "api_key", "apikey", "x-encrypt", "x-signature",
+}
+
+// defaultRedactor is the redactor of the handler installed by SetupWithConfig.
+var defaultRedactor = newRedactor(DefaultRedactKeys)
+
+// redactor masks the values of a fixed set of keys, compared case-insensitively.
+type redactor struct {
+	keys map[string]bool
+}
+
+func newRedactor(keys []string) *redactor {
+	return &redactor{keys: toKeySet(keys)}
+}
+
+// replaceAttr is used as slog.HandlerOptions.ReplaceAttr.
+func (r *redactor) replaceAttr(_ []string, a slog.Attr) slog.Attr {
+	if r.keys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, redacted)
+	}
+	return a
+}
+
+func (r *redactor) redact(v any, extra ...string) any {
+	keys := r.keys
+	if len(extra) > 0 {
+		keys = toKeySet(extra)
+		for key := range r.keys {
+			keys[key] = true
+		}
+	}
+	return redactValue(v, keys)
+}
+
+// Redact returns a copy of v where values under the configured redact keys, or any of the
+// extra keys, are masked. It walks the maps and slices produced by json.Unmarshal.
+func Redact(v any, extra ...string) any {
+	return defaultRedactor.redact(v, extra...)
+}
+
+func redactValue(v any, keys map[string]bool) any {
+	switch value := v.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(value))
+		for key, item := range value {
+			if keys[strings.ToLower(key)] {
+				result[key] = redacted
+				continue
+			}
+			result[key] = redactValue(item, keys)
+		}
+		return result
+	case []any:
+		result := make([]any, len(value))
+		for i, item := range value {
+			result[i] = redactValue(item, keys)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+func toKeySet(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[strings.ToLower(key)] = true
+	}
+	return set
+}
@@ -0,0 +1,183 @@
+package metrics
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	_redis "github.com/redis/go-redis/v9"
+)
+
+const namespace = "app"
+
+// Registry holds every collector exposed by Handler. It is separate from the prometheus default
+// registry so only what this service registers is exported.
+var Registry = newRegistry()
+
+var (
+	HTTPRequests = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route pattern and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	HandlerErrors = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "handler_errors_total",
+		Help:      "Errors returned by HTTP and message handlers.",
+	}, []string{"component"})
+
+	RabbitPublished = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rabbitmq_published_total",
+		Help:      "RabbitMQ publish attempts by exchange, routing key and result.",
+	}, []string{"exchange", "routing_key", "result"})
+
+	RabbitConsumed = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rabbitmq_consumed_total",
+		Help:      "RabbitMQ deliveries handled by queue and result.",
+	}, []string{"queue", "result"})
+
+	Retries = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "retries_total",
+		Help:      "Retries by component and operation.",
+	}, []string{"component", "operation"})
+
+	MQTTConnected = promauto.With(Registry).NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "mqtt_connected",
+		Help:      "1 while the MQTT client is connected to the broker.",
+	})
+)
+
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+func newRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// Handler serves the registry in the prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Result maps an error to the result label value.
+func Result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultSuccess
+}
+
+// RegisterDBStats exports the sql.DB connection pool stats under the given database name.
+func RegisterDBStats(name string, db *sql.DB) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterRedisPool exports the go-redis connection pool stats with the client label set to name.
+// Every client is served by the same collector, so only a duplicate name is an error.
+func RegisterRedisPool(name string, stats func() *_redis.PoolStats) error {
+	return redisPools.add(name, stats)
+}
+
+// RegisterWorkerPool exports the running and capacity gauges of a worker pool.
+func RegisterWorkerPool(name string, running, capacity func() int) error {
+	labels := prometheus.Labels{"pool": name}
+	if err := Registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "worker_pool_running",
+		Help:        "Workers currently running in the pool.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(running()) })); err != nil {
+		return err
+	}
+	return Registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "worker_pool_capacity",
+		Help:        "Capacity of the worker pool.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(capacity()) }))
+}
+
+type redisPoolCollector struct {
+	mu         sync.RWMutex
+	registered bool
+	pools      map[string]func() *_redis.PoolStats
+}
+
+var redisPools = &redisPoolCollector{pools: make(map[string]func() *_redis.PoolStats)}
+
+func (c *redisPoolCollector) add(name string, stats func() *_redis.PoolStats) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.pools[name]; ok {
+		return fmt.Errorf("redis pool %q is already registered", name)
+	}
+	if !c.registered {
+		if err := Registry.Register(c); err != nil {
+			return err
+		}
+		c.registered = true
+	}
+	c.pools[name] = stats
+	return nil
+}
+
+var (
+	redisHits     = prometheus.NewDesc(namespace+"_redis_pool_hits_total", "Free connections found in the pool.", []string{"client"}, nil)
+	redisMisses   = prometheus.NewDesc(namespace+"_redis_pool_misses_total", "Free connections not found in the pool.", []string{"client"}, nil)
+	redisTimeouts = prometheus.NewDesc(namespace+"_redis_pool_timeouts_total", "Waits for a pool connection that timed out.", []string{"client"}, nil)
+	redisTotal    = prometheus.NewDesc(namespace+"_redis_pool_connections", "Connections in the pool.", []string{"client"}, nil)
+	redisIdle     = prometheus.NewDesc(namespace+"_redis_pool_idle_connections", "Idle connections in the pool.", []string{"client"}, nil)
+	redisStale    = prometheus.NewDesc(namespace+"_redis_pool_stale_connections_total", "Stale connections removed from the pool.", []string{"client"}, nil)
+)
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- redisHits
+	ch <- redisMisses
+	ch <- redisTimeouts
+	ch <- redisTotal
+	ch <- redisIdle
+	ch <- redisStale
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for name, poolStats := range c.pools {
+		stats := poolStats()
+		if stats == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(redisHits, prometheus.CounterValue, float64(stats.Hits), name)
+		ch <- prometheus.MustNewConstMetric(redisMisses, prometheus.CounterValue, float64(stats.Misses), name)
+		ch <- prometheus.MustNewConstMetric(redisTimeouts, prometheus.CounterValue, float64(stats.Timeouts), name)
+		ch <- prometheus.MustNewConstMetric(redisTotal, prometheus.GaugeValue, float64(stats.TotalConns), name)
+		ch <- prometheus.MustNewConstMetric(redisIdle, prometheus.GaugeValue, float64(stats.IdleConns), name)
+		ch <- prometheus.MustNewConstMetric(redisStale, prometheus.CounterValue, float64(stats.StaleConns), name)
+	}
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"boilerplate-go/internal/pkg/logger"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccessLogOptions struct {
+	// SampleRate is the share of successful requests that are logged, between 0 and 1.
+	// Responses with status >= 500 are always logged.
+	SampleRate      float64
+	SkipPaths       []string
+	LogRequestBody  bool
+	LogResponseBody bool
+	MaxBodySize     int
+	RedactFields    []string
+}
+
+func DefaultAccessLogOptions() *AccessLogOptions {
+	return &AccessLogOptions{
+		SampleRate:      1,
+		SkipPaths:       []string{"/health", "/healthz", "/metrics"},
+		LogRequestBody:  false,
+		LogResponseBody: false,
+		MaxBodySize:     4096,
+		RedactFields:    nil,
+	}
+}
+
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body  *bytes.Buffer
+	limit int
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	if remaining := w.limit - w.body.Len(); remaining > 0 {
+		w.body.Write(b[:min(len(b), remaining)])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// AccessLogMiddleware writes one structured record per request on the http channel. Register it
+// first so the latency covers the whole chain; the request id and user id are picked up from
+// the request context once RequestInit and AuthMiddleware have run.
+func AccessLogMiddleware(opts *AccessLogOptions) gin.HandlerFunc {
+	skip := make(map[string]bool, len(opts.SkipPaths))
+	for _, path := range opts.SkipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if skip[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+
+		var requestBody []byte
+		if opts.LogRequestBody && c.Request.Body != nil {
+			body, err := io.ReadAll(c.Request.Body)
+			if err == nil {
+				c.Request.Body = io.NopCloser(bytes.NewReader(body))
+				requestBody = body[:min(len(body), opts.MaxBodySize)]
+			}
+		}
+
+		var capture *bodyCaptureWriter
+		if opts.LogResponseBody {
+			capture = &bodyCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}, limit: opts.MaxBodySize}
+			c.Writer = capture
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < http.StatusInternalServerError && opts.SampleRate < 1 && rand.Float64() >= opts.SampleRate {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		attrs := []any{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Int64("latencyMs", time.Since(start).Milliseconds()),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("clientIp", c.ClientIP()),
+		}
+		if requestBody != nil {
+			attrs = append(attrs, slog.Any("requestBody",
+				describeBody(requestBody, c.ContentType(), opts.MaxBodySize, opts.RedactFields)))
+		}
+		if capture != nil {
+			attrs = append(attrs, slog.Any("responseBody",
+				describeBody(capture.body.Bytes(), c.Writer.Header().Get("Content-Type"), opts.MaxBodySize, opts.RedactFields)))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		logger.FromContext(c.Request.Context()).
+			With(slog.String("channel", logger.ChannelHTTP)).
+			Log(c.Request.Context(), level, "request completed", attrs...)
+	}
+}
+
+// describeBody returns a redacted representation of a captured body. JSON and form bodies are
+// parsed and redacted field by field; other content types are never logged verbatim.
+func describeBody(body []byte, contentType string, limit int, redactFields []string) any {
+	if len(body) == 0 {
+		return nil
+	}
+
+	switch {
+	case strings.Contains(contentType, "application/json"):
+		var parsed any
+		if err := json.Unmarshal(body, &parsed); err == nil {
+			return logger.Redact(parsed, redactFields...)
+		}
+		if len(body) >= limit {
+			return "[truncated json]"
+		}
+		return "[invalid json]"
+	case strings.Contains(contentType, "application/x-www-form-urlencoded"):
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "[invalid form]"
+		}
+		form := make(map[string]any, len(values))
+		for key, value := range values {
+			form[key] = strings.Join(value, ",")
+		}
+		return logger.Redact(form, redactFields...)
+	default:
+		return "[omitted " + contentType + "]"
+	}
+}
@@ -2,12 +2,11 @@ package middleware
 
 import (
 	_type "boilerplate-go/internal/common/type"
+	"boilerplate-go/internal/pkg/apperror"
 	"boilerplate-go/internal/pkg/helper"
 	"boilerplate-go/internal/pkg/jwt"
 	"boilerplate-go/internal/pkg/logger"
-	"errors"
 	"github.com/gin-gonic/gin"
-	"net/http"
 	"strings"
 )
 
@@ -16,23 +15,25 @@ func AuthMiddleware(auth jwt.IJWTAuth) gin.HandlerFunc {
 		send := c.MustGet("send").(func(r *_type.Response))
 		token := c.GetHeader("Authorization")
 		if token == "" {
-			send(helper.ParseResponse(&_type.Response{Code: http.StatusBadRequest, Message: "token not found"}))
+			send(helper.ParseError(apperror.Unauthorized("token not found")))
 			return
 		}
 
-		logger.Debug.Println("token", token)
 		parts := strings.Split(token, " ")
 		if len(parts) < 2 {
-			send(helper.ParseResponse(&_type.Response{Code: http.StatusBadRequest, Message: "invalid token format", Error: errors.New("invalid token format")}))
+			send(helper.ParseError(apperror.Unauthorized("invalid token format")))
 			return
 		}
-		claims, err := auth.ValidateToken(parts[1])
+		claims, err := auth.ValidateToken(c.Request.Context(), parts[1])
 		if err != nil {
-			send(helper.ParseResponse(&_type.Response{Code: http.StatusBadRequest, Message: "invalid token", Error: err}))
+			send(helper.ParseError(apperror.Unauthorized("invalid token").Wrap(err)))
 			return
 		}
 
 		c.Set("auth", claims)
+		if id, ok := claims["id"]; ok {
+			c.Request = c.Request.WithContext(logger.With(c.Request.Context(), "userId", id))
+		}
 		c.Next()
 	}
 }
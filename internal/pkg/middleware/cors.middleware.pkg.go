@@ -1,26 +1,190 @@
 package middleware
 
 import (
+	"boilerplate-go/internal/pkg/helper"
+	"boilerplate-go/internal/pkg/logger"
 	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func CorsMiddleware() gin.HandlerFunc {
+type CorsOptions struct {
+	// AllowOrigins holds exact origins ("https://app.example.com"), wildcard subdomains
+	// ("https://*.example.com") or "*" for any origin.
+	AllowOrigins     []string
+	AllowOriginRegex []string
+	AllowMethods     []string
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// DefaultCorsOptions reads the allowed origins from CORS_ALLOW_ORIGINS (comma separated, "*" when
+// unset) and enables credentials when CORS_ALLOW_CREDENTIALS is "1".
+func DefaultCorsOptions() *CorsOptions {
+	origins := []string{"*"}
+	if value := os.Getenv("CORS_ALLOW_ORIGINS"); value != "" {
+		origins = splitAndTrim(value)
+	}
+
+	return &CorsOptions{
+		AllowOrigins: origins,
+		AllowMethods: []string{
+			http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+		},
+		AllowHeaders: []string{
+			"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Session", "Authorization", "Accept", "Origin",
+			"Cache-Control", "X-Requested-With", "x-time", "x-encrypt", "x-nonce", "x-signature", "x-tenant", "version",
+			helper.RequestIDHeader,
+		},
+		ExposeHeaders: []string{
+			"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", helper.RequestIDHeader,
+		},
+		AllowCredentials: os.Getenv("CORS_ALLOW_CREDENTIALS") == "1",
+		MaxAge:           12 * time.Hour,
+	}
+}
+
+type originMatcher struct {
+	any       bool
+	exact     map[string]bool
+	wildcards [][2]string
+	patterns  []*regexp.Regexp
+}
+
+func newOriginMatcher(opts *CorsOptions) *originMatcher {
+	m := &originMatcher{exact: make(map[string]bool)}
+
+	for _, origin := range opts.AllowOrigins {
+		origin = strings.ToLower(origin)
+		switch {
+		case origin == "*":
+			m.any = true
+		case strings.Contains(origin, "*"):
+			parts := strings.SplitN(origin, "*", 2)
+			m.wildcards = append(m.wildcards, [2]string{parts[0], parts[1]})
+		default:
+			m.exact[origin] = true
+		}
+	}
+
+	// Patterns must match the whole origin, "https://app\.example\.com" must not accept
+	// "https://app.example.com.evil.org".
+	for _, pattern := range opts.AllowOriginRegex {
+		m.patterns = append(m.patterns, regexp.MustCompile("^(?:"+pattern+")$"))
+	}
+
+	return m
+}
+
+func (m *originMatcher) match(origin string) bool {
+	if m.any {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if m.exact[origin] {
+		return true
+	}
+
+	for _, wildcard := range m.wildcards {
+		prefix, suffix := wildcard[0], wildcard[1]
+		if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			if !strings.Contains(origin[len(prefix):len(origin)-len(suffix)], "/") {
+				return true
+			}
+		}
+	}
+
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CorsMiddleware answers preflight requests and sets CORS headers for allowed origins.
+// Invalid AllowOriginRegex entries panic when the middleware is built. AllowCredentials is
+// ignored when "*" is allowed, since any site could then read credentialed responses.
+func CorsMiddleware(opts *CorsOptions) gin.HandlerFunc {
+	matcher := newOriginMatcher(opts)
+	allowCredentials := opts.AllowCredentials && !matcher.any
+	if opts.AllowCredentials && matcher.any && logger.Warning != nil {
+		logger.Warning.Println("CORS credentials are disabled because every origin is allowed.")
+	}
+	allowMethods := strings.Join(opts.AllowMethods, ", ")
+	allowHeaders := strings.Join(opts.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(opts.ExposeHeaders, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge.Seconds()))
+
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set(
-			"Access-Control-Allow-Headers",
-			"Content-Type, Content-Length, Accept-Encoding, X-CSRF-Session, Authorization, accept, origin, Cache-Control, X-Requested-With",
-		)
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-
-		if c.Request.Method == "OPTIONS" {
+		origin := c.GetHeader("Origin")
+		isPreflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+
+		if !matcher.match(origin) {
+			if isPreflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if matcher.any {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if allowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if isPreflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			header.Set("Access-Control-Allow-Methods", allowMethods)
+			if allowHeaders != "" {
+				header.Set("Access-Control-Allow-Headers", allowHeaders)
+			} else if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+				header.Set("Access-Control-Allow-Headers", requested)
+			}
+			if opts.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", maxAge)
+			}
 			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
+		if exposeHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", exposeHeaders)
+		}
+
 		c.Next()
 	}
 }
+
+func splitAndTrim(value string) []string {
+	var result []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
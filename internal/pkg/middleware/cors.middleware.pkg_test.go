@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newCorsRouter(opts *CorsOptions) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CorsMiddleware(opts))
+	r.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+	return r
+}
+
+func corsOptions(origins ...string) *CorsOptions {
+	return &CorsOptions{
+		AllowOrigins:  origins,
+		AllowMethods:  []string{http.MethodGet, http.MethodPost},
+		AllowHeaders:  []string{"Content-Type", "Authorization"},
+		ExposeHeaders: []string{"X-Request-ID"},
+		MaxAge:        time.Hour,
+	}
+}
+
+func serveCors(r *gin.Engine, method, origin string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/ping", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCorsAllowedOrigin(t *testing.T) {
+	r := newCorsRouter(corsOptions("https://app.example.com"))
+
+	w := serveCors(r, http.MethodGet, "https://app.example.com", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Allow-Origin = %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Expose-Headers"); got != "X-Request-ID" {
+		t.Errorf("Expose-Headers = %q", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Origin" {
+		t.Errorf("Vary = %q, want Origin", got)
+	}
+}
+
+func TestCorsDisallowedOrigin(t *testing.T) {
+	r := newCorsRouter(corsOptions("https://app.example.com"))
+
+	w := serveCors(r, http.MethodGet, "https://evil.example.org", nil)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Allow-Origin = %q, want none", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Origin" {
+		t.Errorf("Vary = %q, want Origin", got)
+	}
+
+	w = serveCors(r, http.MethodOptions, "https://evil.example.org", map[string]string{
+		"Access-Control-Request-Method": http.MethodPost,
+	})
+	if w.Code != http.StatusForbidden {
+		t.Errorf("preflight status = %d, want 403", w.Code)
+	}
+}
+
+func TestCorsPreflight(t *testing.T) {
+	r := newCorsRouter(corsOptions("https://*.example.com"))
+
+	w := serveCors(r, http.MethodOptions, "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method":  http.MethodPost,
+		"Access-Control-Request-Headers": "Content-Type",
+	})
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", w.Code)
+	}
+
+	want := map[string]string{
+		"Access-Control-Allow-Origin":  "https://app.example.com",
+		"Access-Control-Allow-Methods": "GET, POST",
+		"Access-Control-Allow-Headers": "Content-Type, Authorization",
+		"Access-Control-Max-Age":       "3600",
+	}
+	for key, value := range want {
+		if got := w.Header().Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+
+	vary := w.Header().Values("Vary")
+	if len(vary) == 0 || vary[0] != "Origin" {
+		t.Errorf("Vary = %q, want Origin first", vary)
+	}
+}
+
+func TestCorsOriginRegexIsAnchored(t *testing.T) {
+	opts := corsOptions()
+	opts.AllowOriginRegex = []string{`https://app\.example\.com`}
+	r := newCorsRouter(opts)
+
+	if got := serveCors(r, http.MethodGet, "https://app.example.com", nil).Header().Get("Access-Control-Allow-Origin"); got == "" {
+		t.Error("exact match rejected")
+	}
+	if got := serveCors(r, http.MethodGet, "https://app.example.com.evil.org", nil).Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("suffixed origin allowed: %q", got)
+	}
+}
+
+func TestCorsCredentialsWithWildcard(t *testing.T) {
+	opts := corsOptions("*")
+	opts.AllowCredentials = true
+	r := newCorsRouter(opts)
+
+	w := serveCors(r, http.MethodGet, "https://evil.example.org", nil)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Allow-Origin = %q, want *", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Allow-Credentials = %q, want none", got)
+	}
+}
+
+func TestCorsCredentialsWithExactOrigin(t *testing.T) {
+	opts := corsOptions("https://app.example.com")
+	opts.AllowCredentials = true
+	r := newCorsRouter(opts)
+
+	w := serveCors(r, http.MethodGet, "https://app.example.com", nil)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Allow-Origin = %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Allow-Credentials = %q, want true", got)
+	}
+}
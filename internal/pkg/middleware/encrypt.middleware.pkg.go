@@ -2,9 +2,12 @@ package middleware
 
 import (
 	_type "boilerplate-go/internal/common/type"
+	"boilerplate-go/internal/pkg/apperror"
 	"boilerplate-go/internal/pkg/helper"
+	"boilerplate-go/internal/pkg/logger"
 	"boilerplate-go/internal/pkg/redis"
 	"bytes"
+	"crypto/hmac"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -18,6 +21,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	maxClockSkew   = 30 * time.Second
+	minNonceLength = 16
+	maxNonceLength = 128
+)
+
 type JwtUser struct {
 	ID    int  `json:"id"`
 	Is2FA bool `json:"is_2fa"`
@@ -47,7 +56,7 @@ var whitelistedPaths = map[string][]string{
 func EncryptMiddleware(rds redis.IRedis) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		send := c.MustGet("send").(func(r *_type.Response))
-		if err := validateHeaders(c, send); err != nil {
+		if err := validateHeaders(c, rds, send); err != nil {
 			return
 		}
 		if err := validateJwt(c, rds, send); err != nil {
@@ -60,8 +69,8 @@ func EncryptMiddleware(rds redis.IRedis) gin.HandlerFunc {
 	}
 }
 
-func validateHeaders(c *gin.Context, send func(r *_type.Response)) error {
-	timeHeader := c.GetHeader("x-time")
+func validateHeaders(c *gin.Context, rds redis.IRedis, send func(r *_type.Response)) error {
+	timeHeader := c.GetHeader(helper.HeaderTime)
 	encryptHeader := c.GetHeader("x-encrypt")
 	tenantHeader := c.GetHeader("x-tenant")
 	host := c.Request.Header.Get("Origin")
@@ -70,42 +79,42 @@ func validateHeaders(c *gin.Context, send func(r *_type.Response)) error {
 	}
 
 	if tenantHeader != os.Getenv("APP_TENANT") && os.Getenv("DEV") != "1" {
-		send(helper.ParseResponse(&_type.Response{
-			Code:    http.StatusForbidden,
-			Message: "Invalid Tenant",
-			Error:   errors.New("invalid Tenant"),
-		}))
+		send(helper.ParseError(apperror.Forbidden("Invalid Tenant")))
 		return errors.New("invalid Tenant")
 	}
 
 	if host != "" && os.Getenv("DEV_HOST") != "" && os.Getenv("DEV_HOST") != "1" {
 		appURL := os.Getenv("APP_URL")
 		if !strings.Contains(host, appURL) {
-			send(helper.ParseResponse(&_type.Response{
-				Code:    http.StatusForbidden,
-				Message: "Invalid Host",
-				Error:   errors.New("invalid Host"),
-			}))
+			send(helper.ParseError(apperror.Forbidden("Invalid Host")))
 			return errors.New("invalid Host")
 		}
 	}
 
 	if os.Getenv("DEV") != "1" && !isPathExempted(c.Request.URL.Path) {
+		if c.GetHeader(helper.HeaderSignature) != "" {
+			if err := validateSignature(c, rds); err != nil {
+				send(helper.ParseError(apperror.Unauthorized("Invalid Signature").Wrap(err)))
+				return err
+			}
+			return nil
+		}
+
+		// The legacy x-time/x-encrypt pair can be replayed within HEADER_TIME, so it is only
+		// accepted while HEADER_LEGACY=1 during the migration to signed requests.
+		if os.Getenv("HEADER_LEGACY") != "1" {
+			send(helper.ParseError(apperror.Unauthorized("Invalid Headers").Wrap(errors.New("request signature is required"))))
+			return errors.New("request signature is required")
+		}
+		logger.FromContext(c.Request.Context()).Warn("deprecated unsigned request headers accepted", "method", c.Request.Method, "path", c.Request.URL.Path)
+
 		intTimeHeader, err := strconv.Atoi(timeHeader)
 		if err != nil {
-			send(helper.ParseResponse(&_type.Response{
-				Code:    http.StatusForbidden,
-				Message: "Invalid Headers",
-				Error:   errors.New("invalid Headers"),
-			}))
+			send(helper.ParseError(apperror.Unauthorized("Invalid Headers")))
 			return err
 		}
 		if err := validateTime(intTimeHeader, encryptHeader); err != nil {
-			send(helper.ParseResponse(&_type.Response{
-				Code:    http.StatusForbidden,
-				Message: "Invalid Headers",
-				Error:   err,
-			}))
+			send(helper.ParseError(apperror.Unauthorized("Invalid Headers").Wrap(err)))
 			return err
 		}
 	}
@@ -117,31 +126,19 @@ func validateJwt(c *gin.Context, rds redis.IRedis, send func(r *_type.Response))
 	if authHeader != "" {
 		user, err := parseJwt(authHeader)
 		if err != nil {
-			send(helper.ParseResponse(&_type.Response{
-				Code:    http.StatusForbidden,
-				Message: "Invalid Token",
-				Error:   err,
-			}))
+			send(helper.ParseError(apperror.Unauthorized("Invalid Token").Wrap(err)))
 			return err
 		}
 
 		if user.Is2FA {
 			keyCache := os.Getenv("APP_TENANT") + ":" + strconv.Itoa(user.ID) + ":2fa"
-			token, err := rds.Get(keyCache)
-			if err != nil {
-				send(helper.ParseResponse(&_type.Response{
-					Code:    http.StatusForbidden,
-					Message: "not authorized",
-					Error:   err,
-				}))
+			token, err := rds.Get(c.Request.Context(), keyCache)
+			if err != nil && !redis.IsNil(err) {
+				send(helper.ParseError(apperror.Internal("not authorized").Wrap(err)))
 				return err
 			}
 			if token == "" && c.Request.URL.Path != "/auth/2fa-authenticate" {
-				send(helper.ParseResponse(&_type.Response{
-					Code:    http.StatusForbidden,
-					Message: "not authorized #2",
-					Error:   errors.New("not authorized #2"),
-				}))
+				send(helper.ParseError(apperror.Forbidden("not authorized #2")))
 				return errors.New("not authorized #2")
 			}
 		}
@@ -159,31 +156,19 @@ func validateRequestBody(c *gin.Context, send func(r *_type.Response)) error {
 		}
 
 		if err := c.ShouldBind(&payload); err != nil {
-			send(helper.ParseResponse(&_type.Response{
-				Code:    http.StatusForbidden,
-				Message: "Failed to read request body",
-				Error:   err,
-			}))
+			send(helper.ParseError(apperror.BadRequest("Failed to read request body").Wrap(err)))
 			return err
 		}
 
-		decryptedData, err := helper.DecryptAESCBC(payload.Data)
+		decryptedData, err := helper.DecryptPayload(payload.Data)
 		if err != nil {
-			send(helper.ParseResponse(&_type.Response{
-				Code:    http.StatusForbidden,
-				Message: "Failed to decrypt data",
-				Error:   err,
-			}))
+			send(helper.ParseError(apperror.BadRequest("Failed to decrypt data").Wrap(err)))
 			return err
 		}
 
 		var bodyData map[string]interface{}
 		if err := json.Unmarshal([]byte(decryptedData), &bodyData); err != nil {
-			send(helper.ParseResponse(&_type.Response{
-				Code:    http.StatusForbidden,
-				Message: "Failed to parse data",
-				Error:   err,
-			}))
+			send(helper.ParseError(apperror.BadRequest("Failed to parse data").Wrap(err)))
 			return err
 		}
 
@@ -218,11 +203,8 @@ func validateTime(timeHeader int, encryptHeader string) error {
 		return errors.New("invalid headers")
 	}
 
-	timeValue := time.Unix(int64(timeHeader), 0)
-
-	delta := time.Since(timeValue).Seconds()
-	if delta > float64(helper.GetEnvAsInt("HEADER_TIME")) {
-		return errors.New("invalid time")
+	if err := validateTimestamp(int64(timeHeader)); err != nil {
+		return err
 	}
 
 	message := os.Getenv("HEADER_CODE") + ":" + strconv.Itoa(timeHeader)
@@ -231,12 +213,81 @@ func validateTime(timeHeader int, encryptHeader string) error {
 		return err
 	}
 
-	if computed != encryptHeader {
+	if !hmac.Equal([]byte(computed), []byte(encryptHeader)) {
 		return errors.New("invalid encryption")
 	}
 	return nil
 }
 
+// validateTimestamp accepts timestamps at most HEADER_TIME seconds old and rejects
+// timestamps further in the future than maxClockSkew.
+func validateTimestamp(timestamp int64) error {
+	delta := time.Since(time.Unix(timestamp, 0))
+	if delta > signatureWindow() {
+		return errors.New("invalid time")
+	}
+	if delta < -maxClockSkew {
+		return errors.New("time is in the future")
+	}
+	return nil
+}
+
+func signatureWindow() time.Duration {
+	return time.Duration(helper.GetEnvAsInt("HEADER_TIME")) * time.Second
+}
+
+// validateSignature verifies a signature over method, path, query, body hash, time and nonce,
+// then remembers the nonce in redis for the signature window so the request cannot be replayed.
+func validateSignature(c *gin.Context, rds redis.IRedis) error {
+	timestamp, err := strconv.ParseInt(c.GetHeader(helper.HeaderTime), 10, 64)
+	if err != nil || timestamp <= 0 {
+		return errors.New("invalid headers")
+	}
+
+	nonce := c.GetHeader(helper.HeaderNonce)
+	if len(nonce) < minNonceLength || len(nonce) > maxNonceLength {
+		return errors.New("invalid nonce")
+	}
+
+	if err := validateTimestamp(timestamp); err != nil {
+		return err
+	}
+
+	var body []byte
+	if c.Request.Body != nil {
+		body, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	valid, err := helper.VerifyRequestSignature(
+		c.GetHeader(helper.HeaderSignature),
+		c.Request.Method,
+		c.Request.URL.Path,
+		c.Request.URL.Query(),
+		body,
+		timestamp,
+		nonce,
+	)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return errors.New("invalid signature")
+	}
+
+	stored, err := rds.SetNX(c.Request.Context(), os.Getenv("APP_TENANT")+":nonce:"+nonce, timestamp, signatureWindow()+maxClockSkew)
+	if err != nil {
+		return err
+	}
+	if !stored {
+		return errors.New("nonce already used")
+	}
+	return nil
+}
+
 func parseJwt(token string) (*JwtUser, error) {
 	parts := strings.Split(token, ".")
 	if len(parts) < 2 {
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	_type "boilerplate-go/internal/common/type"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETagMiddleware makes ResponseInit tag successful GET responses with an ETag and answer a
+// matching If-None-Match with 304 Not Modified.
+func ETagMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet {
+			c.Set("etag", true)
+		}
+		c.Next()
+	}
+}
+
+// responseETag hashes the payload without the debug section, which changes on every request.
+// The ETag is weak because the bytes sent still include that section in debug mode.
+func responseETag(response _type.ResponseAPI) (string, error) {
+	response.Debug = nil
+	data, err := json.Marshal(response)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// writeETag sets the ETag header and reports whether the request already holds that version.
+func writeETag(c *gin.Context, response _type.ResponseAPI) bool {
+	etag, err := responseETag(response)
+	if err != nil {
+		return false
+	}
+
+	c.Header("ETag", etag)
+	if c.Writer.Header().Get("Cache-Control") == "" {
+		c.Header("Cache-Control", "private, no-cache")
+	}
+	return etagMatches(c.GetHeader("If-None-Match"), etag)
+}
+
+// etagMatches applies the weak comparison of RFC 9110 to an If-None-Match list.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
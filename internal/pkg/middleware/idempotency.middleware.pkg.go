@@ -0,0 +1,286 @@
+package middleware
+
+import (
+	_type "boilerplate-go/internal/common/type"
+	"boilerplate-go/internal/pkg/apperror"
+	"boilerplate-go/internal/pkg/helper"
+	"boilerplate-go/internal/pkg/logger"
+	"boilerplate-go/internal/pkg/redis"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	idempotencyProcessing = "processing"
+	idempotencyCompleted  = "completed"
+)
+
+// The scripts only touch the key while it still holds this request's processing record, so a
+// request that outlived LockTTL cannot release or overwrite the record of the one that took over.
+const (
+	releaseIdempotencyScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`
+
+	completeIdempotencyScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+	return 1
+end
+return 0`
+)
+
+var errIdempotencyNotHeld = errors.New("idempotency key not held")
+
+type IdempotencyOptions struct {
+	Prefix string
+	// TTL is how long a completed response is replayed.
+	TTL time.Duration
+	// LockTTL bounds how long a request is considered in flight, in case the instance handling it
+	// dies before storing the response.
+	LockTTL time.Duration
+	// KeyFunc scopes keys to the caller so two users cannot collide on the same key.
+	KeyFunc       func(c *gin.Context) string
+	MaxKeyLength  int
+	ReplayHeaders []string
+}
+
+func DefaultIdempotencyOptions() *IdempotencyOptions {
+	return &IdempotencyOptions{
+		Prefix:        os.Getenv("APP_TENANT") + ":idempotency:",
+		TTL:           24 * time.Hour,
+		LockTTL:       time.Minute,
+		KeyFunc:       ResponseCacheByUser,
+		MaxKeyLength:  255,
+		ReplayHeaders: []string{"Content-Type", "Location", "ETag", "Cache-Control"},
+	}
+}
+
+type idempotencyRecord struct {
+	State string `json:"state"`
+	// Token identifies the request holding a processing record.
+	Token       string              `json:"token,omitempty"`
+	Fingerprint string              `json:"fingerprint"`
+	Status      int                 `json:"status,omitempty"`
+	Header      map[string][]string `json:"header,omitempty"`
+	Body        []byte              `json:"body,omitempty"`
+}
+
+// IdempotencyMiddleware makes POST, PUT and PATCH requests carrying an Idempotency-Key header
+// safe to retry. The first request runs and its response, as written by ResponseInit's send, is
+// stored; repeats with the same body get those exact bytes back. A repeat arriving while the
+// first is still running, or reusing the key for a different request, is answered with 409.
+// Server errors are not stored so the client can retry them. Register it after ResponseInit,
+// AuthMiddleware and any middleware that rewrites the request body.
+func IdempotencyMiddleware(rds redis.IRedis, opts *IdempotencyOptions) gin.HandlerFunc {
+	if opts == nil {
+		opts = DefaultIdempotencyOptions()
+	}
+
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+		if idempotencyKey == "" || !isIdempotentMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		send := c.MustGet("send").(func(r *_type.Response))
+		if len(idempotencyKey) > opts.MaxKeyLength {
+			send(helper.ParseError(apperror.BadRequest("Idempotency-Key is too long")))
+			return
+		}
+
+		fingerprint, err := requestFingerprint(c)
+		if err != nil {
+			send(helper.ParseError(apperror.BadRequest("Failed to read request body").Wrap(err)))
+			return
+		}
+
+		ctx := c.Request.Context()
+		key := opts.Prefix + hashKey(opts.KeyFunc(c)+"\n"+c.Request.Method+" "+c.FullPath()+"\n"+idempotencyKey)
+
+		token, err := helper.GenerateID()
+		if err != nil {
+			send(helper.ParseError(apperror.Internal("Failed to generate idempotency token").Wrap(err)))
+			return
+		}
+
+		pendingData, _ := json.Marshal(idempotencyRecord{State: idempotencyProcessing, Token: token, Fingerprint: fingerprint})
+		pending := string(pendingData)
+		acquired, err := rds.SetNX(ctx, key, pending, opts.LockTTL)
+		if err != nil {
+			logger.FromContext(ctx).Warn("idempotency store unavailable", "error", err)
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			replayIdempotent(c, rds, key, fingerprint, send)
+			return
+		}
+
+		completed := false
+		defer func() {
+			if !completed {
+				releaseIdempotencyKey(ctx, rds, key, pending)
+			}
+		}()
+
+		capture := &responseCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = capture
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		record := idempotencyRecord{
+			State:       idempotencyCompleted,
+			Fingerprint: fingerprint,
+			Status:      status,
+			Header:      make(map[string][]string, len(opts.ReplayHeaders)),
+			Body:        capture.body.Bytes(),
+		}
+		for _, name := range opts.ReplayHeaders {
+			if values := c.Writer.Header().Values(name); len(values) > 0 {
+				record.Header[name] = values
+			}
+		}
+
+		data, err := json.Marshal(record)
+		if err != nil {
+			logger.FromContext(ctx).Warn("failed to encode idempotent response", "error", err)
+			return
+		}
+
+		stored, err := compareAndSetIdempotency(context.WithoutCancel(ctx), rds, key, pending, completeIdempotencyScript, func(pipe redis.Pipeliner) {
+			pipe.Set(key, data, opts.TTL)
+		}, data, opts.TTL.Milliseconds())
+		switch {
+		case err != nil:
+			logger.FromContext(ctx).Warn("failed to store idempotent response", "error", err)
+		case !stored:
+			// LockTTL expired and another request owns the key now; its record is left alone.
+			logger.FromContext(ctx).Warn("idempotency key expired before the response was stored")
+			completed = true
+		default:
+			completed = true
+		}
+	}
+}
+
+func replayIdempotent(c *gin.Context, rds redis.IRedis, key, fingerprint string, send func(r *_type.Response)) {
+	record, err := redis.GetJSON[idempotencyRecord](c.Request.Context(), rds, key)
+	if redis.IsNil(err) {
+		// The first request failed and released the key between both calls.
+		send(helper.ParseError(apperror.New(apperror.CodeIdempotencyInProgress, http.StatusConflict, "A request with this Idempotency-Key is being processed")))
+		return
+	}
+	if err != nil {
+		send(helper.ParseError(apperror.Internal("Failed to read idempotency key").Wrap(err)))
+		return
+	}
+
+	switch {
+	case record.Fingerprint != fingerprint:
+		send(helper.ParseError(apperror.New(apperror.CodeIdempotencyMismatch, http.StatusConflict, "Idempotency-Key was already used for a different request")))
+	case record.State != idempotencyCompleted:
+		send(helper.ParseError(apperror.New(apperror.CodeIdempotencyInProgress, http.StatusConflict, "A request with this Idempotency-Key is being processed")))
+	default:
+		for name, values := range record.Header {
+			for _, value := range values {
+				c.Writer.Header().Add(name, value)
+			}
+		}
+		c.Header(IdempotencyReplayedHeader, "true")
+		c.Abort()
+		c.Status(record.Status)
+		_, _ = c.Writer.Write(record.Body)
+	}
+}
+
+// releaseIdempotencyKey drops the in-flight marker after a failure, including a panic, so the
+// client can retry with the same key. A marker that already belongs to another request is kept.
+func releaseIdempotencyKey(ctx context.Context, rds redis.IRedis, key, pending string) {
+	_, err := compareAndSetIdempotency(context.WithoutCancel(ctx), rds, key, pending, releaseIdempotencyScript, func(pipe redis.Pipeliner) {
+		pipe.Del(key)
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to release idempotency key", "error", err)
+	}
+}
+
+// compareAndSetIdempotency runs script when key still holds pending and reports whether it did.
+// Clients without lua support, such as Memory, get the same guarantee from WATCH.
+func compareAndSetIdempotency(ctx context.Context, rds redis.IRedis, key, pending, script string, fallback func(pipe redis.Pipeliner), args ...interface{}) (bool, error) {
+	result, err := rds.Eval(ctx, script, []string{key}, append([]interface{}{pending}, args...)...)
+	if errors.Is(err, redis.ErrNotSupported) {
+		return compareAndSetIdempotencyWatch(ctx, rds, key, pending, fallback)
+	}
+	if err != nil {
+		return false, err
+	}
+	n, ok := result.(int64)
+	return ok && n != 0, nil
+}
+
+func compareAndSetIdempotencyWatch(ctx context.Context, rds redis.IRedis, key, pending string, fn func(pipe redis.Pipeliner)) (bool, error) {
+	err := rds.Watch(ctx, func(tx redis.Tx) error {
+		value, err := tx.Get(ctx, key)
+		if redis.IsNil(err) || (err == nil && value != pending) {
+			return errIdempotencyNotHeld
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			fn(pipe)
+			return nil
+		})
+	}, key)
+	if errors.Is(err, errIdempotencyNotHeld) || errors.Is(err, redis.ErrTxFailed) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func isIdempotentMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+// requestFingerprint hashes the method, path, query and body, then restores the body for the
+// handler.
+func requestFingerprint(c *gin.Context) (string, error) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return "", err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "?" + c.Request.URL.Query().Encode() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashKey(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"boilerplate-go/internal/pkg/metrics"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsMiddleware records request count and latency by route pattern, so path parameters do
+// not explode label cardinality. Unmatched routes share the "unmatched" label.
+func MetricsMiddleware(skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if skip[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := c.Writer.Status()
+		labels := []string{c.Request.Method, route, strconv.Itoa(status)}
+
+		metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+		metrics.HTTPDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		if status >= http.StatusInternalServerError {
+			metrics.HandlerErrors.WithLabelValues("http").Inc()
+		}
+	}
+}
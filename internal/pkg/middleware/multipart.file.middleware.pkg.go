@@ -3,9 +3,9 @@ package middleware
 import (
 	"boilerplate-go/internal/common/enum"
 	_type "boilerplate-go/internal/common/type"
+	"boilerplate-go/internal/pkg/apperror"
 	"boilerplate-go/internal/pkg/helper"
 	"io"
-	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -23,11 +23,7 @@ func MultipartFormMiddleware(fields []FieldOpts) gin.HandlerFunc {
 
 		form, err := c.MultipartForm()
 		if err != nil {
-			send(helper.ParseResponse(&_type.Response{
-				Code:    http.StatusInternalServerError,
-				Message: "Failed retrieving files",
-				Error:   err,
-			}))
+			send(helper.ParseError(apperror.BadRequest("Failed retrieving files").Wrap(err)))
 			return
 		}
 
@@ -40,32 +36,23 @@ func MultipartFormMiddleware(fields []FieldOpts) gin.HandlerFunc {
 			}
 
 			for _, fileHeader := range files {
+				// The upload was already parsed, so failing to open, read or close its temp file is
+				// a server error rather than a bad request.
 				file, err := fileHeader.Open()
 				if err != nil {
-					send(helper.ParseResponse(&_type.Response{
-						Code:    http.StatusInternalServerError,
-						Message: "Failed reading file",
-						Error:   err,
-					}))
+					send(helper.ParseError(apperror.Internal("Failed reading file").Wrap(err)))
 					return
 				}
 
 				fileBuffer, err := io.ReadAll(file)
 				if err != nil {
-					send(helper.ParseResponse(&_type.Response{
-						Code:    http.StatusInternalServerError,
-						Message: "Failed reading file content",
-						Error:   err,
-					}))
+					_ = file.Close()
+					send(helper.ParseError(apperror.Internal("Failed reading file content").Wrap(err)))
 					return
 				}
 				err = file.Close()
 				if err != nil {
-					send(helper.ParseResponse(&_type.Response{
-						Code:    http.StatusInternalServerError,
-						Message: "Failed close file",
-						Error:   err,
-					}))
+					send(helper.ParseError(apperror.Internal("Failed close file").Wrap(err)))
 					return
 				}
 
@@ -82,22 +69,14 @@ func MultipartFormMiddleware(fields []FieldOpts) gin.HandlerFunc {
 				if field.Name == enum.IMAGE.ToString() {
 					isValid := enum.IMAGE.IsValidImage(&bufferedFile)
 					if !isValid {
-						send(helper.ParseResponse(&_type.Response{
-							Code:    http.StatusBadRequest,
-							Message: "Please upload a valid image file",
-							Error:   err,
-						}))
+						send(helper.ParseError(apperror.Validation("Please upload a valid image file").Wrap(err)))
 						return
 					}
 				}
 				if field.Name == enum.VIDEO.ToString() {
 					isValid := enum.VIDEO.IsValidVideo(&bufferedFile)
 					if !isValid {
-						send(helper.ParseResponse(&_type.Response{
-							Code:    http.StatusBadRequest,
-							Message: "Please upload a valid video file",
-							Error:   err,
-						}))
+						send(helper.ParseError(apperror.Validation("Please upload a valid video file").Wrap(err)))
 						return
 					}
 				}
@@ -108,17 +87,11 @@ func MultipartFormMiddleware(fields []FieldOpts) gin.HandlerFunc {
 
 		for _, field := range fields {
 			if len(bufferedFiles[field.Name]) < field.Min {
-				send(helper.ParseResponse(&_type.Response{
-					Code:    http.StatusBadRequest,
-					Message: "Minimum " + field.Name + " is " + strconv.Itoa(field.Min),
-				}))
+				send(helper.ParseError(apperror.Validation("Minimum " + field.Name + " is " + strconv.Itoa(field.Min))))
 				return
 			}
 			if len(bufferedFiles[field.Name]) > field.Max {
-				send(helper.ParseResponse(&_type.Response{
-					Code:    http.StatusBadRequest,
-					Message: "Maximum " + field.Name + " is " + strconv.Itoa(field.Max),
-				}))
+				send(helper.ParseError(apperror.Validation("Maximum " + field.Name + " is " + strconv.Itoa(field.Max))))
 				return
 			}
 		}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	_type "boilerplate-go/internal/common/type"
+	"boilerplate-go/internal/pkg/helper"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ResponseFormatEnvelope = "envelope"
+	ResponseFormatProblem  = "problem"
+
+	ContentTypeProblemJSON = "application/problem+json"
+)
+
+// ResponseFormat selects the error format used by ResponseInit for a route group. A request
+// that explicitly accepts application/problem+json always gets problem details.
+func ResponseFormat(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("responseFormat", format)
+		c.Next()
+	}
+}
+
+// negotiateFormat returns the format for an error response. Successful responses always use the
+// envelope.
+func negotiateFormat(c *gin.Context) string {
+	for _, part := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mediaType == ContentTypeProblemJSON {
+			return ResponseFormatProblem
+		}
+	}
+
+	if format := c.GetString("responseFormat"); format != "" {
+		return format
+	}
+	return ResponseFormatEnvelope
+}
+
+// problemDetails maps a response onto RFC 7807. The type is "about:blank" unless
+// PROBLEM_TYPE_BASE_URL is set, in which case the lower-cased error code is appended to it.
+func problemDetails(c *gin.Context, r *_type.Response) *_type.ProblemDetails {
+	problem := &_type.ProblemDetails{
+		Type:      "about:blank",
+		Title:     http.StatusText(r.Code),
+		Status:    r.Code,
+		Detail:    r.Message,
+		Instance:  c.Request.URL.Path,
+		RequestID: c.GetString("requestId"),
+	}
+
+	if section := errorSection(r); section != nil {
+		problem.Code = section.Code
+		problem.Errors = section.Details
+		if base := helper.GetEnv("PROBLEM_TYPE_BASE_URL"); base != "" {
+			problem.Type = strings.TrimSuffix(base, "/") + "/" + strings.ToLower(section.Code)
+		}
+	}
+
+	if problem.Detail == problem.Title {
+		problem.Detail = ""
+	}
+	return problem
+}
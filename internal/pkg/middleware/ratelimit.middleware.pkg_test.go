@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"boilerplate-go/internal/pkg/redis"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMemoryRateLimiterAllowsUpToLimit(t *testing.T) {
+	limiter := NewMemoryRateLimiter()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Allow(ctx, "key", 3, time.Hour)
+		if err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+		if !result.Allowed {
+			t.Fatalf("request %d rejected", i+1)
+		}
+		if want := 3 - i - 1; result.Remaining != want {
+			t.Errorf("request %d remaining = %d, want %d", i+1, result.Remaining, want)
+		}
+	}
+
+	result, err := limiter.Allow(ctx, "key", 3, time.Hour)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if result.Allowed || result.Remaining != 0 {
+		t.Fatalf("request over the limit = %+v, want rejected with 0 remaining", result)
+	}
+
+	other, err := limiter.Allow(ctx, "other", 3, time.Hour)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if !other.Allowed {
+		t.Fatal("another key shared the bucket")
+	}
+}
+
+func TestMemoryRateLimiterResetFollowsOldestHit(t *testing.T) {
+	limiter := NewMemoryRateLimiter()
+	ctx := context.Background()
+
+	first, err := limiter.Allow(ctx, "key", 5, time.Hour)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if first.ResetAfter != time.Hour {
+		t.Errorf("first reset = %v, want %v", first.ResetAfter, time.Hour)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	second, err := limiter.Allow(ctx, "key", 5, time.Hour)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if second.ResetAfter > time.Hour-10*time.Millisecond {
+		t.Errorf("second reset = %v, want the oldest hit plus the window", second.ResetAfter)
+	}
+}
+
+func TestMemoryRateLimiterWindowExpires(t *testing.T) {
+	limiter := NewMemoryRateLimiter()
+	ctx := context.Background()
+
+	if result, _ := limiter.Allow(ctx, "key", 1, 20*time.Millisecond); !result.Allowed {
+		t.Fatal("first request rejected")
+	}
+	if result, _ := limiter.Allow(ctx, "key", 1, 20*time.Millisecond); result.Allowed {
+		t.Fatal("second request allowed inside the window")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if result, _ := limiter.Allow(ctx, "key", 1, 20*time.Millisecond); !result.Allowed {
+		t.Fatal("request rejected after the window passed")
+	}
+}
+
+func TestMemoryRateLimiterNonPositiveLimit(t *testing.T) {
+	limiter := NewMemoryRateLimiter()
+
+	for _, limit := range []int{0, -1} {
+		result, err := limiter.Allow(context.Background(), "key", limit, time.Minute)
+		if err != nil {
+			t.Fatalf("Allow(limit %d): %v", limit, err)
+		}
+		if result.Allowed || result.Remaining != 0 || result.ResetAfter != time.Minute {
+			t.Errorf("Allow(limit %d) = %+v, want rejected with a full window reset", limit, result)
+		}
+	}
+}
+
+type evalRedis struct {
+	redis.IRedis
+	result interface{}
+	err    error
+	keys   []string
+	args   []interface{}
+}
+
+func (r *evalRedis) Eval(_ context.Context, _ string, keys []string, args ...interface{}) (interface{}, error) {
+	r.keys, r.args = keys, args
+	return r.result, r.err
+}
+
+func TestRedisRateLimiterParsesScriptResult(t *testing.T) {
+	rds := &evalRedis{result: []interface{}{int64(0), int64(0), int64(1500)}}
+
+	result, err := NewRedisRateLimiter(rds).Allow(context.Background(), "key", 10, time.Minute)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if result.Allowed || result.Limit != 10 || result.Remaining != 0 || result.ResetAfter != 1500*time.Millisecond {
+		t.Errorf("result = %+v", result)
+	}
+	if len(rds.keys) != 1 || rds.keys[0] != "key" {
+		t.Errorf("script keys = %v, want [key]", rds.keys)
+	}
+	if rds.args[0] != time.Minute.Milliseconds() || rds.args[1] != 10 {
+		t.Errorf("script args = %v, want window ms and limit first", rds.args)
+	}
+}
+
+func TestRedisRateLimiterErrors(t *testing.T) {
+	tests := map[string]*evalRedis{
+		"eval error":   {err: errors.New("connection refused")},
+		"wrong length": {result: []interface{}{int64(1)}},
+		"wrong types":  {result: []interface{}{"1", int64(0), int64(0)}},
+		"not an array": {result: "OK"},
+	}
+
+	for name, rds := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewRedisRateLimiter(rds).Allow(context.Background(), "key", 1, time.Second); err == nil {
+				t.Fatal("Allow succeeded, want error")
+			}
+		})
+	}
+}
+
+func newRateLimitRouter(limiter RateLimiter, opts *RateLimitOptions) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ResponseInit())
+	r.GET("/limited", RateLimitMiddleware(limiter, opts), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return r
+}
+
+func serveRateLimited(r *gin.Engine) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/limited", nil))
+	return w
+}
+
+func TestRateLimitMiddlewareHeaders(t *testing.T) {
+	r := newRateLimitRouter(NewMemoryRateLimiter(), DefaultRateLimitOptions(2, 30*time.Second))
+
+	w := serveRateLimited(r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	for header, want := range map[string]string{
+		"RateLimit-Limit":     "2",
+		"RateLimit-Remaining": "1",
+		"RateLimit-Reset":     "30",
+		"Retry-After":         "",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	serveRateLimited(r)
+	w = serveRateLimited(r)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Errorf("RateLimit-Remaining = %q, want 0", got)
+	}
+	if got, reset := w.Header().Get("Retry-After"), w.Header().Get("RateLimit-Reset"); got == "" || got != reset {
+		t.Errorf("Retry-After = %q, want it to match RateLimit-Reset %q", got, reset)
+	}
+}
+
+func TestRateLimitMiddlewareFailsOpen(t *testing.T) {
+	limiter := NewRedisRateLimiter(&evalRedis{err: errors.New("connection refused")})
+	r := newRateLimitRouter(limiter, DefaultRateLimitOptions(1, time.Minute))
+
+	w := serveRateLimited(r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 when the limiter is down", w.Code)
+	}
+	if got := w.Header().Get("RateLimit-Limit"); got != "" {
+		t.Errorf("RateLimit-Limit = %q, want none", got)
+	}
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"boilerplate-go/internal/pkg/helper"
+	"boilerplate-go/internal/pkg/redis"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+}
+
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error)
+}
+
+// slidingWindowScript keeps one sorted set member per request scored by its timestamp in ms.
+// It returns {allowed, remaining, resetAfterMs}, where the reset is when the oldest hit leaves the
+// window. A non-positive limit rejects every request.
+const slidingWindowScript = `
+local key = KEYS[1]
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local member = ARGV[3]
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+local remaining = 0
+if count < limit then
+	redis.call('ZADD', key, now, member)
+	redis.call('PEXPIRE', key, window)
+	allowed = 1
+	remaining = limit - count - 1
+end
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local reset = window
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, remaining, reset}
+`
+
+type redisRateLimiter struct {
+	redis redis.IRedis
+}
+
+// NewRedisRateLimiter returns a sliding window limiter shared by every instance using the same redis.
+func NewRedisRateLimiter(rds redis.IRedis) RateLimiter {
+	return &redisRateLimiter{redis: rds}
+}
+
+func (l *redisRateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error) {
+	id, err := helper.GenerateID()
+	if err != nil {
+		return nil, err
+	}
+	member := strconv.FormatInt(time.Now().UnixNano(), 10) + "-" + id
+
+	raw, err := l.redis.Eval(ctx, slidingWindowScript, []string{key}, window.Milliseconds(), limit, member)
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := raw.([]interface{})
+	if !ok || len(values) != 3 {
+		return nil, fmt.Errorf("unexpected rate limit script result: %v", raw)
+	}
+
+	allowed, okAllowed := values[0].(int64)
+	remaining, okRemaining := values[1].(int64)
+	reset, okReset := values[2].(int64)
+	if !okAllowed || !okRemaining || !okReset {
+		return nil, errors.New("unexpected rate limit script result types")
+	}
+
+	return &RateLimitResult{
+		Allowed:    allowed == 1,
+		Limit:      limit,
+		Remaining:  int(remaining),
+		ResetAfter: time.Duration(reset) * time.Millisecond,
+	}, nil
+}
+
+// memoryRateLimitSweepInterval is how often Allow drops the windows of keys that went quiet.
+const memoryRateLimitSweepInterval = time.Minute
+
+type memoryWindow struct {
+	hits   []time.Time
+	window time.Duration
+}
+
+type memoryRateLimiter struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryWindow
+	lastSweep time.Time
+}
+
+// NewMemoryRateLimiter returns a process local sliding window limiter, meant for tests and single instances.
+func NewMemoryRateLimiter() RateLimiter {
+	return &memoryRateLimiter{entries: make(map[string]*memoryWindow), lastSweep: time.Now()}
+}
+
+func (l *memoryRateLimiter) Allow(_ context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) >= memoryRateLimitSweepInterval {
+		l.sweep(now)
+	}
+
+	entry := l.entries[key]
+	if entry == nil {
+		entry = &memoryWindow{}
+		l.entries[key] = entry
+	}
+	entry.window = window
+
+	hits := entry.hits
+	start := 0
+	for start < len(hits) && !hits[start].After(now.Add(-window)) {
+		start++
+	}
+	hits = hits[start:]
+
+	if len(hits) < limit {
+		entry.hits = append(hits, now)
+		return &RateLimitResult{
+			Allowed:    true,
+			Limit:      limit,
+			Remaining:  limit - len(entry.hits),
+			ResetAfter: entry.hits[0].Add(window).Sub(now),
+		}, nil
+	}
+
+	entry.hits = hits
+	resetAfter := window
+	if len(hits) > 0 {
+		resetAfter = hits[0].Add(window).Sub(now)
+	}
+	return &RateLimitResult{
+		Allowed:    false,
+		Limit:      limit,
+		Remaining:  0,
+		ResetAfter: resetAfter,
+	}, nil
+}
+
+// sweep deletes the keys whose last hit is outside their window; the caller holds mu.
+func (l *memoryRateLimiter) sweep(now time.Time) {
+	for key, entry := range l.entries {
+		if len(entry.hits) == 0 || !entry.hits[len(entry.hits)-1].After(now.Add(-entry.window)) {
+			delete(l.entries, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	_type "boilerplate-go/internal/common/type"
+	"boilerplate-go/internal/pkg/apperror"
+	"boilerplate-go/internal/pkg/helper"
+	"boilerplate-go/internal/pkg/logger"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PanicReporter forwards a recovered panic to an external error tracker.
+type PanicReporter func(c *gin.Context, recovered interface{}, stack []byte)
+
+// Recovery turns handler panics into a 500 ResponseAPI. Register it after RequestInit and
+// ResponseInit so the request id is logged and the standard envelope is used.
+func Recovery(reporter PanicReporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			stack := debug.Stack()
+			logger.FromContext(c.Request.Context()).Error("panic recovered",
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"panic", fmt.Sprint(recovered),
+				"stack", string(stack),
+			)
+
+			if reporter != nil {
+				reportPanic(reporter, c, recovered, stack)
+			}
+
+			if isBrokenPipe(recovered) || c.Writer.Written() {
+				c.Abort()
+				return
+			}
+
+			if send, ok := c.Get("send"); ok {
+				if fn, ok := send.(func(r *_type.Response)); ok {
+					fn(helper.ParseError(apperror.Internal("Internal Server Error").Wrap(fmt.Errorf("panic: %v", recovered))))
+					return
+				}
+			}
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, _type.ResponseAPI{
+				Message: "Internal Server Error",
+			})
+		}()
+
+		c.Next()
+	}
+}
+
+func reportPanic(reporter PanicReporter, c *gin.Context, recovered interface{}, stack []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error.Printf("Panic reporter failed: %v\n", r)
+		}
+	}()
+	reporter(c, recovered, stack)
+}
+
+func isBrokenPipe(recovered interface{}) bool {
+	err, ok := recovered.(error)
+	if !ok {
+		return false
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		var syscallErr *os.SyscallError
+		if errors.As(opErr, &syscallErr) {
+			msg := strings.ToLower(syscallErr.Error())
+			return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+		}
+	}
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
@@ -1,21 +1,39 @@
 package middleware
 
 import (
+	"boilerplate-go/internal/pkg/helper"
+	"boilerplate-go/internal/pkg/logger"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func RequestInit() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Set("requestId", uuid.New().String())
+		requestID := c.GetHeader(helper.RequestIDHeader)
+		if !helper.IsValidRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+		c.Set("requestId", requestID)
+		c.Header(helper.RequestIDHeader, requestID)
 		version := c.Request.Header.Get("version")
 		if version == "" {
 			version = "1.0.0"
 		}
 		c.Set("version", version)
 		c.Set("start-time", time.Now())
+
+		ctx := helper.ContextWithRequestID(c.Request.Context(), requestID)
+		if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+			ctx = logger.With(ctx, "traceId", spanCtx.TraceID().String(), "spanId", spanCtx.SpanID().String())
+		}
+		if tenant := c.GetHeader("x-tenant"); tenant != "" {
+			ctx = logger.With(ctx, "tenant", tenant)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
 		c.Next()
 	}
 }
@@ -2,7 +2,9 @@ package middleware
 
 import (
 	_type "boilerplate-go/internal/common/type"
+	"boilerplate-go/internal/pkg/apperror"
 	"boilerplate-go/internal/pkg/helper"
+	"errors"
 	"net/http"
 	"time"
 
@@ -20,9 +22,22 @@ func ResponseInit() gin.HandlerFunc {
 				r.Code = http.StatusOK
 			}
 
+			if r.Code >= http.StatusBadRequest && negotiateFormat(c) == ResponseFormatProblem {
+				c.Abort()
+				c.Header("Content-Type", ContentTypeProblemJSON)
+				c.JSON(r.Code, problemDetails(c, r))
+				return
+			}
+
 			response := _type.ResponseAPI{
 				Message: r.Message,
 				Data:    r.Data,
+				Error:   errorSection(r),
+			}
+
+			if r.Code < http.StatusMultipleChoices && c.GetBool("etag") && writeETag(c, response) {
+				c.AbortWithStatus(http.StatusNotModified)
+				return
 			}
 
 			if shouldDebug {
@@ -59,3 +74,16 @@ func ResponseInit() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// errorSection returns the stable error code and details for error responses.
+func errorSection(r *_type.Response) *_type.ResponseAPIError {
+	if r.Code < http.StatusBadRequest {
+		return nil
+	}
+
+	var appErr *apperror.Error
+	if errors.As(r.Error, &appErr) {
+		return &_type.ResponseAPIError{Code: appErr.Code, Details: appErr.Details}
+	}
+	return &_type.ResponseAPIError{Code: apperror.CodeForStatus(r.Code)}
+}
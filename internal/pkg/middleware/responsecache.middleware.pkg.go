@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"boilerplate-go/internal/pkg/cache"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ResponseCacheOptions struct {
+	TTL time.Duration
+	// KeyFunc identifies the caller; responses are never shared between callers.
+	KeyFunc func(c *gin.Context) string
+	// VaryHeaders are request headers that select a different representation.
+	VaryHeaders []string
+}
+
+func DefaultResponseCacheOptions() *ResponseCacheOptions {
+	return &ResponseCacheOptions{
+		TTL:         time.Minute,
+		KeyFunc:     ResponseCacheByUser,
+		VaryHeaders: []string{"Accept", "Accept-Language"},
+	}
+}
+
+type cachedResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType"`
+	ETag        string `json:"etag"`
+	Body        []byte `json:"body"`
+}
+
+type responseCaptureWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseCaptureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// ResponseCacheByUser keys on the "id" claim set by AuthMiddleware, or "anonymous".
+func ResponseCacheByUser(c *gin.Context) string {
+	if claims, ok := c.Get("auth"); ok {
+		if data, ok := claims.(map[string]interface{}); ok {
+			if id, ok := data["id"]; ok {
+				return fmt.Sprint(id)
+			}
+		}
+	}
+	return "anonymous"
+}
+
+// ResponseCacheMiddleware caches successful GET responses per route, query string and caller,
+// and tags them with ETags like ETagMiddleware. Register it after AuthMiddleware. A request with
+// Cache-Control no-cache skips the lookup, no-store on either side also skips the write. Nothing
+// is stored in gin debug mode, whose responses carry per-request debug data.
+func ResponseCacheMiddleware(store *cache.Cache, opts *ResponseCacheOptions) gin.HandlerFunc {
+	if opts == nil {
+		opts = DefaultResponseCacheOptions()
+	}
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if c.Request.Method != http.MethodGet || route == "" {
+			c.Next()
+			return
+		}
+		c.Set("etag", true)
+
+		ctx := c.Request.Context()
+		user := opts.KeyFunc(c)
+		key := responseCacheKey(c, route, user, opts.VaryHeaders)
+		requestControl := c.GetHeader("Cache-Control")
+
+		if !hasDirective(requestControl, "no-cache") && !hasDirective(requestControl, "no-store") {
+			if entry, ok, _ := cache.Get[cachedResponse](ctx, store, key); ok {
+				c.Header("ETag", entry.ETag)
+				c.Header("Cache-Control", "private, no-cache")
+				c.Header("X-Cache", "HIT")
+				if entry.ETag != "" && etagMatches(c.GetHeader("If-None-Match"), entry.ETag) {
+					c.AbortWithStatus(http.StatusNotModified)
+					return
+				}
+				c.Abort()
+				c.Data(entry.Status, entry.ContentType, entry.Body)
+				return
+			}
+		}
+
+		c.Header("X-Cache", "MISS")
+		capture := &responseCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = capture
+		c.Next()
+
+		header := c.Writer.Header()
+		if gin.Mode() == gin.DebugMode || c.Writer.Status() != http.StatusOK || capture.body.Len() == 0 ||
+			hasDirective(requestControl, "no-store") || hasDirective(header.Get("Cache-Control"), "no-store") {
+			return
+		}
+
+		entry := cachedResponse{
+			Status:      http.StatusOK,
+			ContentType: header.Get("Content-Type"),
+			ETag:        header.Get("ETag"),
+			Body:        capture.body.Bytes(),
+		}
+		_ = store.Set(ctx, key, entry, opts.TTL, responseRouteTag(route), responseUserTag(user))
+	}
+}
+
+// InvalidateResponseCache drops the cached responses of routes, given as gin full paths such as
+// "/api/v1/users/:id". Services call it after writes that change what those routes return.
+func InvalidateResponseCache(ctx context.Context, store *cache.Cache, routes ...string) error {
+	tags := make([]string, 0, len(routes))
+	for _, route := range routes {
+		tags = append(tags, responseRouteTag(route))
+	}
+	return store.InvalidateTags(ctx, tags...)
+}
+
+// InvalidateUserResponseCache drops every cached response of the given callers.
+func InvalidateUserResponseCache(ctx context.Context, store *cache.Cache, users ...string) error {
+	tags := make([]string, 0, len(users))
+	for _, user := range users {
+		tags = append(tags, responseUserTag(user))
+	}
+	return store.InvalidateTags(ctx, tags...)
+}
+
+// responseCacheKey hashes the variable parts so user ids and query strings never end up in key
+// names. Query parameters are sorted by Encode.
+func responseCacheKey(c *gin.Context, route, user string, varyHeaders []string) string {
+	h := sha256.New()
+	h.Write([]byte(user + "\n" + c.Request.URL.Query().Encode()))
+	for _, name := range varyHeaders {
+		h.Write([]byte("\n" + c.GetHeader(name)))
+	}
+	return "response:" + route + ":" + hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+func responseRouteTag(route string) string {
+	return "response:route:" + route
+}
+
+func responseUserTag(user string) string {
+	return "response:user:" + user
+}
+
+func hasDirective(header, directive string) bool {
+	for _, part := range strings.Split(header, ",") {
+		if strings.EqualFold(strings.TrimSpace(part), directive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TracingMiddleware starts a server span per request, continuing any incoming W3C trace context.
+// Register it before RequestInit so the trace id is attached to the request logger.
+func TracingMiddleware(service string, skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return otelgin.Middleware(service, otelgin.WithFilter(func(r *http.Request) bool {
+		return !skip[r.URL.Path]
+	}))
+}
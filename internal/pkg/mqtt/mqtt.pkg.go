@@ -1,8 +1,11 @@
 package mqtt
 
 import (
+	"boilerplate-go/internal/pkg/helper"
 	"boilerplate-go/internal/pkg/logger"
+	"boilerplate-go/internal/pkg/metrics"
 	"boilerplate-go/internal/pkg/redis"
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -17,9 +20,11 @@ func Setup(config *Config, rds redis.IRedis) (IMqtt, error) {
 	opts.SetUsername(config.Username)
 	opts.SetPassword(config.Password)
 	opts.OnConnect = func(client mqtt.Client) {
+		metrics.MQTTConnected.Set(1)
 		fmt.Println("Connected to IMqtt broker")
 	}
 	opts.OnConnectionLost = func(client mqtt.Client, err error) {
+		metrics.MQTTConnected.Set(0)
 		logger.Error.Printf("Connection lost: %v\n", err)
 	}
 
@@ -51,38 +56,53 @@ func (m *Client) Publish(topic string, qos byte, retained bool, payload any) err
 	return nil
 }
 
+// PublishWithContext publishes payload inside an Envelope carrying the request id from ctx.
+func (m *Client) PublishWithContext(ctx context.Context, topic string, qos byte, retained bool, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return m.Publish(topic, qos, retained, &Envelope{
+		Meta: EnvelopeMeta{RequestID: helper.RequestIDFromContext(ctx)},
+		Data: data,
+	})
+}
+
+// ParseEnvelope decodes a message published with PublishWithContext and restores its request id into ctx.
+func ParseEnvelope(ctx context.Context, msg mqtt.Message) (context.Context, json.RawMessage, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(msg.Payload(), &envelope); err != nil {
+		return ctx, nil, err
+	}
+	if helper.IsValidRequestID(envelope.Meta.RequestID) {
+		ctx = helper.ContextWithRequestID(ctx, envelope.Meta.RequestID)
+	}
+	return ctx, envelope.Data, nil
+}
+
 func (m *Client) Disconnect(timeout uint) {
 	m.client.Disconnect(timeout)
+	metrics.MQTTConnected.Set(0)
 }
 
-func (m *Client) AddClient(clientKey *ClientKey, clientBody *ClientBody, expr time.Duration) error {
+func (m *Client) AddClient(ctx context.Context, clientKey *ClientKey, clientBody *ClientBody, expr time.Duration) error {
 	encBody, err := clientBody.Encrypt()
 	if err != nil {
 		return err
 	}
-	err = m.redis.Set(fmt.Sprintf(`[%q,%q,%q]`, clientKey.MountPoint, clientKey.ClientID, clientKey.Username), encBody, expr)
-	if err != nil {
-		return err
-	}
-	return nil
+	return redis.SetJSON(ctx, m.redis, clientKey.cacheKey(), encBody, expr)
 }
 
-func (m *Client) ExtendTTLClient(clientKey *ClientKey) error {
-	err := m.redis.Expire(fmt.Sprintf(`[%q,%q,%q]`, clientKey.MountPoint, clientKey.ClientID, clientKey.Username), 24*time.Hour)
-	if err != nil {
-		return err
-	}
-	return nil
+func (m *Client) ExtendTTLClient(ctx context.Context, clientKey *ClientKey) error {
+	_, err := m.redis.Expire(ctx, clientKey.cacheKey(), 24*time.Hour)
+	return err
 }
 
-func (m *Client) RemoveClient(clientKey *ClientKey) error {
-	err := m.redis.Del(fmt.Sprintf(`[%q,%q,%q]`, clientKey.MountPoint, clientKey.ClientID, clientKey.Username))
-	if err != nil {
-		return err
-	}
-	return nil
+func (m *Client) RemoveClient(ctx context.Context, clientKey *ClientKey) error {
+	_, err := m.redis.Del(ctx, clientKey.cacheKey())
+	return err
 }
 
 func (m *Client) Close() {
-	m.client.Disconnect(250)
+	m.Disconnect(250)
 }
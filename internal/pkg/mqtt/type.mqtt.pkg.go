@@ -2,6 +2,9 @@ package mqtt
 
 import (
 	"boilerplate-go/internal/pkg/redis"
+	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
@@ -23,19 +26,36 @@ type Config struct {
 type IMqtt interface {
 	Subscribe(topic string, qos byte, callback mqtt.MessageHandler)
 	Publish(topic string, qos byte, retained bool, payload interface{}) error
+	PublishWithContext(ctx context.Context, topic string, qos byte, retained bool, payload interface{}) error
 	Disconnect(timeout uint)
-	AddClient(clientKey *ClientKey, clientBody *ClientBody, expr time.Duration) error
-	RemoveClient(clientKey *ClientKey) error
-	ExtendTTLClient(clientKey *ClientKey) error
+	AddClient(ctx context.Context, clientKey *ClientKey, clientBody *ClientBody, expr time.Duration) error
+	RemoveClient(ctx context.Context, clientKey *ClientKey) error
+	ExtendTTLClient(ctx context.Context, clientKey *ClientKey) error
 	Close()
 }
 
+// Envelope wraps payloads sent with PublishWithContext. MQTT 3 has no message headers, so
+// metadata such as the request id travels next to the data.
+type Envelope struct {
+	Meta EnvelopeMeta    `json:"meta"`
+	Data json.RawMessage `json:"data"`
+}
+
+type EnvelopeMeta struct {
+	RequestID string `json:"requestId,omitempty"`
+}
+
 type ClientKey struct {
 	MountPoint string `json:"mount_point"`
 	ClientID   string `json:"client_id"`
 	Username   string `json:"username"`
 }
 
+// cacheKey is the key VerneMQ's redis auth plugin looks clients up by.
+func (ck *ClientKey) cacheKey() string {
+	return fmt.Sprintf(`[%q,%q,%q]`, ck.MountPoint, ck.ClientID, ck.Username)
+}
+
 type ACL struct {
 	Pattern string `json:"pattern"`
 }
@@ -11,6 +11,11 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+const (
+	returnBuffer        = 128
+	returnDrainInterval = 100 * time.Millisecond
+)
+
 type ChannelManager struct {
 	connManager   *ConnectionManager
 	channel       *amqp.Channel
@@ -20,6 +25,14 @@ type ChannelManager struct {
 	retryInterval time.Duration
 	ctx           context.Context
 	cancel        context.CancelFunc
+	topologies    []*Topology
+
+	// watchReturns collects broker returns of mandatory publishes. Only publisher channels set
+	// it; consumer channels never wait for returns.
+	watchReturns bool
+	returnsMu    sync.Mutex
+	returns      <-chan amqp.Return
+	expected     map[string]*amqp.Return
 }
 
 func NewChannelManager(ctx context.Context, connManager *ConnectionManager) *ChannelManager {
@@ -31,6 +44,7 @@ func NewChannelManager(ctx context.Context, connManager *ConnectionManager) *Cha
 		ctx:           ctx,
 		cancel:        cancel,
 		closed:        false,
+		expected:      make(map[string]*amqp.Return),
 	}
 }
 
@@ -84,11 +98,59 @@ func (cm *ChannelManager) setupChannel() (*amqp.Channel, error) {
 		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
 	}
 
+	for _, topology := range cm.topologies {
+		if err := topology.declare(ch); err != nil {
+			ch.Close()
+			return nil, err
+		}
+	}
+
 	go cm.channelMonitor(ch)
+	if cm.watchReturns {
+		returns := ch.NotifyReturn(make(chan amqp.Return, returnBuffer))
+		cm.returnsMu.Lock()
+		cm.returns = returns
+		cm.returnsMu.Unlock()
+		go cm.returnMonitor(returns)
+	}
 
 	return ch, nil
 }
 
+// DeclareTopology declares t on the current channel and again on every channel opened after a
+// reconnect. Declaring a topology equal to one already registered is a no-op, so callers may
+// build a fresh Topology per publish.
+func (cm *ChannelManager) DeclareTopology(t *Topology) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if cm.closed {
+		return errors.New("channel manager is closed")
+	}
+
+	for _, declared := range cm.topologies {
+		if declared.equal(t) {
+			return nil
+		}
+	}
+
+	if cm.channel == nil {
+		// The new channel declares every registered topology, t included.
+		cm.topologies = append(cm.topologies, t)
+		if _, err := cm.setupChannelWithRetry(); err != nil {
+			cm.topologies = cm.topologies[:len(cm.topologies)-1]
+			return err
+		}
+		return nil
+	}
+
+	if err := t.declare(cm.channel); err != nil {
+		return err
+	}
+	cm.topologies = append(cm.topologies, t)
+	return nil
+}
+
 func (cm *ChannelManager) channelMonitor(ch *amqp.Channel) {
 	chErr := make(chan *amqp.Error)
 	ch.NotifyClose(chErr)
@@ -104,6 +166,75 @@ func (cm *ChannelManager) channelMonitor(ch *amqp.Channel) {
 	}
 }
 
+// returnMonitor keeps the return channel drained so the amqp reader never blocks on it. Returns
+// are always collected under returnsMu, see takeReturn.
+func (cm *ChannelManager) returnMonitor(returns <-chan amqp.Return) {
+	ticker := time.NewTicker(returnDrainInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cm.ctx.Done():
+			return
+		case <-ticker.C:
+			cm.returnsMu.Lock()
+			open := cm.drainReturns(returns)
+			cm.returnsMu.Unlock()
+			if !open {
+				return
+			}
+		}
+	}
+}
+
+// drainReturns must be called with returnsMu held. It reports whether returns is still open.
+func (cm *ChannelManager) drainReturns(returns <-chan amqp.Return) bool {
+	for {
+		select {
+		case ret, ok := <-returns:
+			if !ok {
+				return false
+			}
+			if _, waiting := cm.expected[ret.MessageId]; waiting {
+				cm.expected[ret.MessageId] = &ret
+			} else {
+				logger.Warning.Printf("Message %s returned by broker: %d %s\n", ret.MessageId, ret.ReplyCode, ret.ReplyText)
+			}
+		default:
+			return true
+		}
+	}
+}
+
+// expectReturn registers interest in a return of the mandatory message messageID. Call it
+// before publishing and takeReturn once the publish is confirmed. A message id already in
+// flight is rejected with ErrPublishInFlight.
+func (cm *ChannelManager) expectReturn(messageID string) error {
+	cm.returnsMu.Lock()
+	defer cm.returnsMu.Unlock()
+
+	if _, ok := cm.expected[messageID]; ok {
+		return fmt.Errorf("%w: %s", ErrPublishInFlight, messageID)
+	}
+	cm.expected[messageID] = nil
+	return nil
+}
+
+// takeReturn returns the broker return of messageID, if any. The broker sends a return before
+// the confirm of the same message and the amqp reader hands it over before processing that
+// confirm, so after a confirm the return is either collected already or waiting in the channel.
+func (cm *ChannelManager) takeReturn(messageID string) *amqp.Return {
+	cm.returnsMu.Lock()
+	defer cm.returnsMu.Unlock()
+
+	if cm.returns != nil {
+		cm.drainReturns(cm.returns)
+	}
+	ret := cm.expected[messageID]
+	delete(cm.expected, messageID)
+	return ret
+}
+
 func (cm *ChannelManager) reconnect() {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
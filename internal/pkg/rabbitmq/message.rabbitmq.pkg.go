@@ -1,6 +1,9 @@
 package rabbitmq
 
 import (
+	"boilerplate-go/internal/pkg/helper"
+	"boilerplate-go/internal/pkg/logger"
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -9,6 +12,8 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+const HeaderRequestID = "x-request-id"
+
 type Message struct {
 	ID          string     `json:"id"`
 	Body        []byte     `json:"content"`
@@ -17,12 +22,19 @@ type Message struct {
 	ContentType string     `json:"content_type"`
 }
 
-func NewMessage(payload interface{}, headers *amqp.Table) (*Message, error) {
+func newMessageID() (string, error) {
 	gid, err := gonanoid.New()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("msg_%s_%d", gid, time.Now().Unix()), nil
+}
+
+func NewMessage(payload interface{}, headers *amqp.Table) (*Message, error) {
+	id, err := newMessageID()
 	if err != nil {
 		return nil, err
 	}
-	id := fmt.Sprintf("msg_%s_%d", gid, time.Now().Unix())
 
 	var body []byte
 	var contentType string
@@ -94,3 +106,29 @@ func (m *Message) GenerateRPCReplyPayload(correlationID string) *amqp.Publishing
 		Headers:       m.Headers,
 	}
 }
+
+// WithRequestID copies the request id carried by ctx into the message headers unless one is set.
+func (m *Message) WithRequestID(ctx context.Context) *Message {
+	requestID := helper.RequestIDFromContext(ctx)
+	if requestID == "" {
+		return m
+	}
+	if m.Headers == nil {
+		m.Headers = amqp.Table{}
+	}
+	if _, exists := m.Headers[HeaderRequestID]; !exists {
+		m.Headers[HeaderRequestID] = requestID
+	}
+	return m
+}
+
+// ContextFromDelivery restores the request id published with the message into ctx.
+func ContextFromDelivery(ctx context.Context, msg *amqp.Delivery) context.Context {
+	if requestID, ok := msg.Headers[HeaderRequestID].(string); ok && helper.IsValidRequestID(requestID) {
+		ctx = helper.ContextWithRequestID(ctx, requestID)
+	}
+	if msg.MessageId != "" {
+		ctx = logger.With(ctx, "messageId", msg.MessageId)
+	}
+	return ctx
+}
@@ -2,6 +2,7 @@ package rabbitmq
 
 import (
 	"boilerplate-go/internal/pkg/logger"
+	"boilerplate-go/internal/pkg/metrics"
 	"context"
 	"encoding/json"
 	"errors"
@@ -12,6 +13,27 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+var (
+	ErrPublishNacked   = errors.New("message nacked by broker")
+	ErrPublishReturned = errors.New("message returned by broker")
+	// ErrPublishInFlight rejects a mandatory publish whose message id is already awaiting a
+	// confirm, since a return could not be matched to either of them.
+	ErrPublishInFlight = errors.New("message id is already being published")
+)
+
+// PublishResult describes a publish confirmed by the broker.
+type PublishResult struct {
+	MessageID   string
+	DeliveryTag uint64
+	// Acked is false when the channel is not in confirm mode and nothing was awaited.
+	Acked bool
+	// Returned is set when a mandatory message could not be routed to any queue.
+	Returned *amqp.Return
+	Attempts int
+	// Response is the decoded reply of an RPC publish.
+	Response interface{}
+}
+
 type Publisher struct {
 	connManager    *ConnectionManager
 	channelManager *ChannelManager
@@ -24,41 +46,53 @@ type Publisher struct {
 }
 
 type PublishOptions struct {
-	QueueOpts    *QueueConfig
-	QueueName    string
-	Exchange     string
+	QueueOpts *QueueConfig
+	QueueName string
+	Exchange  string
+	// RoutingKey defaults to QueueName, which routes through the default exchange.
+	RoutingKey string
+	// Topology is declared once per channel before the first publish, e.g. the exchange.
+	Topology     *Topology
 	Mandatory    bool
 	Immediate    bool
 	MaxRetries   int
 	RetryBackoff time.Duration
-	BatchSize    int
-	IsRPC        bool
+	// ConfirmTimeout bounds the wait for the broker to ack or nack each attempt.
+	ConfirmTimeout time.Duration
+	BatchSize      int
+	IsRPC          bool
 }
 
 func DefaultPublishOptions(queueName string) *PublishOptions {
 	return &PublishOptions{
-		QueueOpts:    nil,
-		Exchange:     "",
-		QueueName:    queueName,
-		Mandatory:    false,
-		Immediate:    false,
-		MaxRetries:   3,
-		RetryBackoff: time.Second * 10,
-		BatchSize:    100,
-		IsRPC:        false,
+		QueueOpts:      nil,
+		Exchange:       "",
+		QueueName:      queueName,
+		RoutingKey:     "",
+		Topology:       nil,
+		Mandatory:      false,
+		Immediate:      false,
+		MaxRetries:     3,
+		RetryBackoff:   time.Second * 10,
+		ConfirmTimeout: time.Second * 5,
+		BatchSize:      100,
+		IsRPC:          false,
 	}
 }
 
 func NewPublisher(ctx context.Context, connManager *ConnectionManager) (*Publisher, error) {
 	ctx, cancel := context.WithCancel(ctx)
 
+	channelManager := NewChannelManager(ctx, connManager)
+	channelManager.watchReturns = true
+
 	pub := &Publisher{
 		connManager:    connManager,
 		maxRetries:     3,
 		retryInterval:  time.Second * 2,
 		ctx:            ctx,
 		cancel:         cancel,
-		channelManager: NewChannelManager(ctx, connManager),
+		channelManager: channelManager,
 	}
 
 	return pub, nil
@@ -104,6 +138,27 @@ func (p *Publisher) Publish(msg *Message, opts *PublishOptions) (interface{}, er
 }
 
 func (p *Publisher) PublishWithContext(ctx context.Context, msg *Message, opts *PublishOptions) (interface{}, error) {
+	result, err := p.PublishWithResult(ctx, msg, opts)
+	if result == nil {
+		return nil, err
+	}
+	return result.Response, err
+}
+
+// PublishWithResult publishes msg and waits for the broker to confirm it. Nacks, confirm
+// timeouts and returns of mandatory messages are retried like any other publish error; the
+// result of the last attempt is returned with the error.
+func (p *Publisher) PublishWithResult(ctx context.Context, msg *Message, opts *PublishOptions) (*PublishResult, error) {
+	msg.WithRequestID(ctx)
+
+	ctx, span := startPublishSpan(ctx, msg, opts)
+	result, err := p.publishWithRetry(ctx, msg, opts)
+	endSpan(span, err)
+
+	return result, err
+}
+
+func (p *Publisher) publishWithRetry(ctx context.Context, msg *Message, opts *PublishOptions) (*PublishResult, error) {
 	if opts.MaxRetries == 0 {
 		opts.MaxRetries = p.maxRetries
 	}
@@ -112,10 +167,12 @@ func (p *Publisher) PublishWithContext(ctx context.Context, msg *Message, opts *
 	}
 
 	var lastErr error
+	var lastResult *PublishResult
 	var replyQueue *amqp.Queue
 
 	for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
 		if attempt > 0 {
+			metrics.Retries.WithLabelValues("rabbitmq", "publish").Inc()
 			if err := p.waitForRetry(ctx, opts, attempt); err != nil {
 				return nil, err
 			}
@@ -128,6 +185,14 @@ func (p *Publisher) PublishWithContext(ctx context.Context, msg *Message, opts *
 			continue
 		}
 
+		if opts.Topology != nil {
+			if err := p.channelManager.DeclareTopology(opts.Topology); err != nil {
+				lastErr = err
+				logger.Warning.Printf("Failed to declare topology on attempt %d: %v\n", attempt, err)
+				continue
+			}
+		}
+
 		if opts.QueueName != "" {
 			var err error
 			replyQueue, err = p.declareQueue(opts.QueueName, opts.IsRPC, opts.QueueOpts)
@@ -138,30 +203,35 @@ func (p *Publisher) PublishWithContext(ctx context.Context, msg *Message, opts *
 			}
 		}
 
+		var payload *amqp.Publishing
 		if opts.IsRPC {
 			if replyQueue == nil {
 				return nil, errors.New("reply queue is not initialized")
 			}
-			payload := msg.GenerateRPCPayload(opts.QueueName, replyQueue.Name)
-			if err := p.publishMessage(ctx, opts, payload); err != nil {
-				lastErr = err
-				logger.Warning.Printf("Failed to publish message on attempt %d: %v\n", attempt, err)
-				continue
-			}
-			return p.consumeReplyQueue(replyQueue, payload)
+			payload = msg.GenerateRPCPayload(opts.QueueName, replyQueue.Name)
 		} else {
-			payload := msg.GeneratePayload()
-			if err := p.publishMessage(ctx, opts, payload); err != nil {
-				lastErr = err
-				logger.Warning.Printf("Failed to publish message on attempt %d: %v\n", attempt, err)
-				continue
-			}
+			payload = msg.GeneratePayload()
+		}
+
+		result, err := p.publishMessage(ctx, opts, payload)
+		if result != nil {
+			result.Attempts = attempt + 1
+			lastResult = result
+		}
+		if err != nil {
+			lastErr = err
+			logger.Warning.Printf("Failed to publish message on attempt %d: %v\n", attempt, err)
+			continue
 		}
 
-		return nil, nil
+		if opts.IsRPC {
+			result.Response, err = p.consumeReplyQueue(replyQueue, payload)
+			return result, err
+		}
+		return result, nil
 	}
 
-	return nil, fmt.Errorf("failed to publish message after %d attempts: %w", opts.MaxRetries, lastErr)
+	return lastResult, fmt.Errorf("failed to publish message after %d attempts: %w", opts.MaxRetries, lastErr)
 }
 
 func (p *Publisher) waitForRetry(ctx context.Context, opts *PublishOptions, attempt int) error {
@@ -173,17 +243,49 @@ func (p *Publisher) waitForRetry(ctx context.Context, opts *PublishOptions, atte
 	return nil
 }
 
-func (p *Publisher) publishMessage(ctx context.Context, opts *PublishOptions, payload *amqp.Publishing) error {
+func (p *Publisher) publishMessage(ctx context.Context, opts *PublishOptions, payload *amqp.Publishing) (*PublishResult, error) {
 	ch, err := p.channelManager.GetChannel()
 
 	if err != nil || ch == nil {
-		return fmt.Errorf("failed to get channel: %w", err)
+		return nil, fmt.Errorf("failed to get channel: %w", err)
 	}
 
-	err = ch.PublishWithContext(
+	routingKey := opts.RoutingKey
+	if routingKey == "" {
+		routingKey = opts.QueueName
+	}
+
+	// Returns are matched to their publish by message id, so every mandatory message needs one.
+	if payload.MessageId == "" {
+		if payload.MessageId, err = newMessageID(); err != nil {
+			return nil, fmt.Errorf("failed to generate message id: %w", err)
+		}
+	}
+
+	if opts.Mandatory {
+		if err = p.channelManager.expectReturn(payload.MessageId); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &PublishResult{MessageID: payload.MessageId}
+	err = p.publishAndConfirm(ctx, ch, opts, routingKey, payload, result)
+	if opts.Mandatory {
+		if ret := p.channelManager.takeReturn(payload.MessageId); ret != nil && err == nil {
+			result.Returned = ret
+			err = fmt.Errorf("%w: %d %s", ErrPublishReturned, ret.ReplyCode, ret.ReplyText)
+		}
+	}
+
+	metrics.RabbitPublished.WithLabelValues(opts.Exchange, routingKey, metrics.Result(err)).Inc()
+	return result, err
+}
+
+func (p *Publisher) publishAndConfirm(ctx context.Context, ch *amqp.Channel, opts *PublishOptions, routingKey string, payload *amqp.Publishing, result *PublishResult) error {
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(
 		ctx,
 		opts.Exchange,
-		opts.QueueName,
+		routingKey,
 		opts.Mandatory,
 		opts.Immediate,
 		*payload,
@@ -191,7 +293,25 @@ func (p *Publisher) publishMessage(ctx context.Context, opts *PublishOptions, pa
 	if err != nil {
 		return fmt.Errorf("failed to publish message: %w", err)
 	}
+	if confirm == nil {
+		return nil
+	}
+	result.DeliveryTag = confirm.DeliveryTag
+
+	if opts.ConfirmTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.ConfirmTimeout)
+		defer cancel()
+	}
 
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for publish confirm: %w", err)
+	}
+	if !acked {
+		return ErrPublishNacked
+	}
+	result.Acked = true
 	return nil
 }
 
@@ -0,0 +1,197 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	HeaderRetryCount    = "x-retry-count"
+	HeaderLastError     = "x-last-error"
+	HeaderOriginalQueue = "x-original-queue"
+
+	maxErrorHeaderLength = 1024
+)
+
+// RetryOptions moves failed messages to delay queues instead of requeueing them. Each delay
+// queue holds messages for its TTL and then dead-letters them back to the work queue, so a
+// failing message no longer blocks or hot-loops a worker.
+type RetryOptions struct {
+	// MaxAttempts counts every handler call, the first one included.
+	MaxAttempts int
+	// Delays[i] is the wait before retry i+1; the last delay is used for later retries.
+	Delays []time.Duration
+	// DeadLetterQueue receives messages that ran out of attempts or failed with a non-retryable
+	// error. It defaults to the queue name with a ".dlq" suffix.
+	DeadLetterQueue string
+	// ConfirmTimeout bounds the wait for the broker to confirm the retry or dead-letter copy
+	// before the original is acknowledged. Zero waits for the confirm without a deadline.
+	ConfirmTimeout time.Duration
+}
+
+func DefaultRetryOptions() *RetryOptions {
+	return &RetryOptions{
+		MaxAttempts:     5,
+		Delays:          []time.Duration{time.Second, 5 * time.Second, 30 * time.Second, 2 * time.Minute},
+		DeadLetterQueue: "",
+		ConfirmTimeout:  5 * time.Second,
+	}
+}
+
+type nonRetryableError struct {
+	err error
+}
+
+func (e *nonRetryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *nonRetryableError) Unwrap() error {
+	return e.err
+}
+
+// NonRetryable marks err so the message goes straight to the dead-letter queue, e.g. for
+// payloads that can never be processed.
+func NonRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &nonRetryableError{err: err}
+}
+
+func IsNonRetryable(err error) bool {
+	var target *nonRetryableError
+	return errors.As(err, &target)
+}
+
+// RetryCount returns how many times the message has been retried so far.
+func RetryCount(msg *amqp.Delivery) int {
+	switch v := msg.Headers[HeaderRetryCount].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+func (r *RetryOptions) delay(retry int) time.Duration {
+	if len(r.Delays) == 0 {
+		return 0
+	}
+	return r.Delays[min(retry, len(r.Delays))-1]
+}
+
+func (r *RetryOptions) deadLetterQueue(queueName string) string {
+	if r.DeadLetterQueue != "" {
+		return r.DeadLetterQueue
+	}
+	return queueName + ".dlq"
+}
+
+// retryQueueName returns the delay queue for delay, or the work queue itself when there is no
+// delay.
+func retryQueueName(queueName string, delay time.Duration) string {
+	if delay <= 0 {
+		return queueName
+	}
+	return fmt.Sprintf("%s.retry.%d", queueName, delay.Milliseconds())
+}
+
+// declareRetryQueues declares the dead-letter queue and one delay queue per distinct delay.
+func (r *RetryOptions) declareRetryQueues(ch *amqp.Channel, queueName string) error {
+	if _, err := ch.QueueDeclare(r.deadLetterQueue(queueName), true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare dead-letter queue: %w", err)
+	}
+
+	declared := make(map[time.Duration]bool, len(r.Delays))
+	for _, delay := range r.Delays {
+		if delay <= 0 || declared[delay] {
+			continue
+		}
+		declared[delay] = true
+
+		_, err := ch.QueueDeclare(retryQueueName(queueName, delay), true, false, false, false, amqp.Table{
+			"x-message-ttl":             delay.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queueName,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to declare retry queue: %w", err)
+		}
+	}
+	return nil
+}
+
+// confirmContext bounds ctx by ConfirmTimeout when it is set. The publish itself ignores ctx, so
+// an already expired context would fail the confirm after the copy went out and the original
+// would be requeued and copied again.
+func (r *RetryOptions) confirmContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.ConfirmTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, r.ConfirmTimeout)
+}
+
+// republish copies msg to queue with the retry headers and waits for the broker confirm, so the
+// original can be acknowledged without losing the message.
+func (r *RetryOptions) republish(ctx context.Context, ch *amqp.Channel, queue, originalQueue string, msg *amqp.Delivery, retries int, handlerErr error) error {
+	headers := amqp.Table{}
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	headers[HeaderRetryCount] = int32(retries)
+	headers[HeaderLastError] = truncate(handlerErr.Error(), maxErrorHeaderLength)
+	headers[HeaderOriginalQueue] = originalQueue
+
+	ctx, cancel := r.confirmContext(ctx)
+	defer cancel()
+
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, "", queue, false, false, amqp.Publishing{
+		Headers:         headers,
+		ContentType:     msg.ContentType,
+		ContentEncoding: msg.ContentEncoding,
+		DeliveryMode:    amqp.Persistent,
+		CorrelationId:   msg.CorrelationId,
+		ReplyTo:         msg.ReplyTo,
+		MessageId:       msg.MessageId,
+		Timestamp:       msg.Timestamp,
+		Type:            msg.Type,
+		AppId:           msg.AppId,
+		Body:            msg.Body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", queue, err)
+	}
+	if confirm == nil {
+		return nil
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to confirm publish to %s: %w", queue, err)
+	}
+	if !acked {
+		return fmt.Errorf("publish to %s was nacked", queue)
+	}
+	return nil
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence, as amqp headers must be
+// valid UTF-8.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
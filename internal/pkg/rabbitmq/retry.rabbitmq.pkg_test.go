@@ -0,0 +1,50 @@
+package rabbitmq
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestConfirmContextWithoutTimeout(t *testing.T) {
+	r := &RetryOptions{}
+
+	ctx, cancel := r.confirmContext(context.Background())
+	defer cancel()
+
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("zero ConfirmTimeout set a deadline")
+	}
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("zero ConfirmTimeout returned a done context: %v", err)
+	}
+}
+
+func TestConfirmContextWithTimeout(t *testing.T) {
+	r := &RetryOptions{ConfirmTimeout: time.Minute}
+
+	ctx, cancel := r.confirmContext(context.Background())
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("ConfirmTimeout did not set a deadline")
+	}
+	if remaining := time.Until(deadline); remaining <= 0 || remaining > time.Minute {
+		t.Fatalf("deadline in %v, want within %v", remaining, time.Minute)
+	}
+}
+
+func TestZeroValueRetryOptions(t *testing.T) {
+	r := &RetryOptions{}
+
+	if got := r.delay(1); got != 0 {
+		t.Errorf("delay(1) = %v, want 0", got)
+	}
+	if got := retryQueueName("jobs", r.delay(1)); got != "jobs" {
+		t.Errorf("retry queue = %q, want the work queue", got)
+	}
+	if got := r.deadLetterQueue("jobs"); got != "jobs.dlq" {
+		t.Errorf("dead-letter queue = %q, want %q", got, "jobs.dlq")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	_type "boilerplate-go/internal/common/type"
 	"boilerplate-go/internal/pkg/helper"
 	"boilerplate-go/internal/pkg/logger"
+	"boilerplate-go/internal/pkg/metrics"
 	"context"
 	"fmt"
 	"net/http"
@@ -16,7 +17,8 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
-type MessageHandler func(msg *amqp.Delivery) (interface{}, error)
+// MessageHandler receives a context carrying the publisher's request id and a logger enriched with it.
+type MessageHandler func(ctx context.Context, msg *amqp.Delivery) (interface{}, error)
 
 type SubscribeOptions struct {
 	QueueOpts     *QueueConfig
@@ -31,6 +33,15 @@ type SubscribeOptions struct {
 	PrefetchCount int
 	MessageBuffer int
 	IsRPC         bool
+	// Topology is declared before the queue, e.g. the exchanges it is bound to.
+	Topology *Topology
+	// Bindings bind the queue to exchanges; an empty Queue stands for QueueName.
+	Bindings []QueueBinding
+	// Retry sends failed messages through delay queues and finally to a dead-letter queue. When
+	// nil, failed messages are rejected without requeueing, so they are dropped unless the queue
+	// has its own x-dead-letter-exchange. Earlier versions requeued them, which redelivered a
+	// failing message forever. RPC requests are never retried.
+	Retry *RetryOptions
 }
 
 func DefaultSubscribeOptions(queueName string, isRPC bool) *SubscribeOptions {
@@ -47,11 +58,15 @@ func DefaultSubscribeOptions(queueName string, isRPC bool) *SubscribeOptions {
 		PrefetchCount: 10,
 		MessageBuffer: 100,
 		IsRPC:         false,
+		Topology:      nil,
+		Bindings:      nil,
+		Retry:         DefaultRetryOptions(),
 	}
 
 	if isRPC {
 		opts.WorkerCount = 1
 		opts.PrefetchCount = 1
+		opts.Retry = nil
 	}
 
 	return opts
@@ -126,6 +141,12 @@ func (s *Subscriber) declareQueue(name string, workerID int, isRPC bool, config
 		return nil, fmt.Errorf("failed to set QoS: %w", err)
 	}
 
+	if s.opts.Topology != nil {
+		if err := s.channelManagers[workerID].DeclareTopology(s.opts.Topology); err != nil {
+			return nil, fmt.Errorf("failed to declare topology: %w", err)
+		}
+	}
+
 	if config == nil {
 		config = DefaultQueueConfig()
 		if isRPC {
@@ -145,6 +166,24 @@ func (s *Subscriber) declareQueue(name string, workerID int, isRPC bool, config
 		return nil, fmt.Errorf("failed to declare queue: %w", err)
 	}
 
+	// Bindings are declared on every (re)consume, so they are restored after a reconnect.
+	bindings := make([]QueueBinding, 0, len(s.opts.Bindings))
+	for _, binding := range s.opts.Bindings {
+		if binding.Queue == "" {
+			binding.Queue = reply.Name
+		}
+		bindings = append(bindings, binding)
+	}
+	if err = bindQueue(ch, bindings...); err != nil {
+		return nil, err
+	}
+
+	if s.opts.Retry != nil && !isRPC {
+		if err = s.opts.Retry.declareRetryQueues(ch, reply.Name); err != nil {
+			return nil, err
+		}
+	}
+
 	return &reply, nil
 }
 
@@ -181,6 +220,7 @@ func (s *Subscriber) runWorker(workerID int) {
 		logger.Debug.Println("Worker", workerID, "consuming...")
 		if err := s.consume(workerID); err != nil {
 			logger.Error.Printf("Worker %d consume error: %v\n", workerID, err)
+			metrics.Retries.WithLabelValues("rabbitmq", "consume").Inc()
 			backoff.sleep()
 			continue
 		}
@@ -251,8 +291,15 @@ func (s *Subscriber) consume(workerID int) error {
 	return fmt.Errorf("consume channel closed")
 }
 
-func (s *Subscriber) processMessage(workerID int, msg *amqp.Delivery) error {
-	response, err := s.handler(msg)
+func (s *Subscriber) processMessage(workerID int, msg *amqp.Delivery) (err error) {
+	ctx, span := startConsumeSpan(ContextFromDelivery(s.ctx, msg), s.opts.QueueName, msg)
+	defer func() { endSpan(span, err) }()
+
+	response, err := s.handler(ctx, msg)
+	metrics.RabbitConsumed.WithLabelValues(s.opts.QueueName, metrics.Result(err)).Inc()
+	if err != nil {
+		metrics.HandlerErrors.WithLabelValues("rabbitmq").Inc()
+	}
 
 	if err != nil {
 		if msg.CorrelationId != "" {
@@ -262,14 +309,14 @@ func (s *Subscriber) processMessage(workerID int, msg *amqp.Delivery) error {
 				Error:   err,
 			}, &msg.Headers)
 			if er != nil {
-				return fmt.Errorf("failed to create error payload: %w", err)
+				return fmt.Errorf("failed to create error payload: %w", er)
 			}
 			if sendErr := s.sendReply(workerID, msg, payload); sendErr != nil {
 				return fmt.Errorf("failed to send error reply: %w", sendErr)
 			}
 		}
-		if err = msg.Reject(true); err != nil {
-			return fmt.Errorf("failed to reject message: %w", err)
+		if failErr := s.handleFailure(ctx, workerID, msg, err); failErr != nil {
+			return fmt.Errorf("handler error: %w (failed to settle message: %w)", err, failErr)
 		}
 		return fmt.Errorf("handler error: %w", err)
 	} else if msg.CorrelationId != "" {
@@ -291,6 +338,54 @@ func (s *Subscriber) processMessage(workerID int, msg *amqp.Delivery) error {
 	return nil
 }
 
+// handleFailure settles a delivery whose handler failed. With retries enabled the message is
+// copied to the next delay queue, or to the dead-letter queue once it ran out of attempts or the
+// error is non-retryable, and the original is acknowledged.
+func (s *Subscriber) handleFailure(ctx context.Context, workerID int, msg *amqp.Delivery, handlerErr error) error {
+	if s.opts.AutoAck {
+		return nil
+	}
+
+	retry := s.opts.Retry
+	if retry == nil || msg.CorrelationId != "" {
+		if retry == nil && msg.CorrelationId == "" {
+			logger.Error.Printf("Dropping message %s from %s, retries are disabled: %v\n", msg.MessageId, s.opts.QueueName, handlerErr)
+		}
+		if err := msg.Reject(false); err != nil {
+			return fmt.Errorf("failed to reject message: %w", err)
+		}
+		return nil
+	}
+
+	ch, err := s.channelManagers[workerID].GetChannel()
+	if err != nil || ch == nil {
+		return fmt.Errorf("failed to get channel: %w", err)
+	}
+
+	retries := RetryCount(msg)
+	target := retry.deadLetterQueue(s.opts.QueueName)
+	if !IsNonRetryable(handlerErr) && retries+1 < retry.MaxAttempts {
+		retries++
+		target = retryQueueName(s.opts.QueueName, retry.delay(retries))
+		metrics.Retries.WithLabelValues("rabbitmq", "redeliver").Inc()
+	} else {
+		logger.Warning.Printf("Moving message %s to %s after %d retries: %v\n", msg.MessageId, target, retries, handlerErr)
+	}
+
+	if err = retry.republish(context.WithoutCancel(ctx), ch, target, s.opts.QueueName, msg, retries, handlerErr); err != nil {
+		// Requeue rather than lose the message when the copy could not be stored.
+		if nackErr := msg.Nack(false, true); nackErr != nil {
+			return fmt.Errorf("failed to requeue message: %w", nackErr)
+		}
+		return err
+	}
+
+	if err = msg.Ack(false); err != nil {
+		return fmt.Errorf("failed to acknowledge message: %w", err)
+	}
+	return nil
+}
+
 func (s *Subscriber) sendReply(workerID int, delivery *amqp.Delivery, msg *Message) error {
 	s.mu.RLock()
 	ch, err := s.channelManagers[workerID].GetChannel()
@@ -371,6 +466,11 @@ func (s *Subscriber) GetWorkerCapacity() int {
 	return s.pool.Cap()
 }
 
+// RegisterMetrics exports the worker pool running and capacity gauges for this queue.
+func (s *Subscriber) RegisterMetrics() error {
+	return metrics.RegisterWorkerPool("rabbitmq:"+s.opts.QueueName, s.GetRunningWorkers, s.GetWorkerCapacity)
+}
+
 func (s *Subscriber) IsHealthy() bool {
 	return s.isRunning.Load() && s.pool.Running() > 0
 }
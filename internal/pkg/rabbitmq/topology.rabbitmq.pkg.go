@@ -0,0 +1,114 @@
+package rabbitmq
+
+import (
+	"fmt"
+	"reflect"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	ExchangeDirect  = amqp.ExchangeDirect
+	ExchangeTopic   = amqp.ExchangeTopic
+	ExchangeFanout  = amqp.ExchangeFanout
+	ExchangeHeaders = amqp.ExchangeHeaders
+)
+
+type ExchangeConfig struct {
+	Name       string
+	Kind       string
+	Durable    bool
+	AutoDelete bool
+	// Internal exchanges only receive messages from other exchanges.
+	Internal bool
+	NoWait   bool
+	Args     amqp.Table
+}
+
+func DefaultExchangeConfig(name, kind string) *ExchangeConfig {
+	return &ExchangeConfig{
+		Name:       name,
+		Kind:       kind,
+		Durable:    true,
+		AutoDelete: false,
+		Internal:   false,
+		NoWait:     false,
+		Args:       nil,
+	}
+}
+
+// QueueBinding routes messages from Exchange to Queue. RoutingKey is a pattern such as
+// "order.*.created" for topic exchanges and is ignored by fanout exchanges; headers exchanges
+// match on Args instead.
+type QueueBinding struct {
+	Queue      string
+	Exchange   string
+	RoutingKey string
+	NoWait     bool
+	Args       amqp.Table
+}
+
+// ExchangeBinding routes messages from the Source exchange to the Destination exchange.
+type ExchangeBinding struct {
+	Destination string
+	Source      string
+	RoutingKey  string
+	NoWait      bool
+	Args        amqp.Table
+}
+
+// Topology is declared in order: exchanges, then exchange bindings. Queues are declared by the
+// Publisher and Subscriber that use them, so queue bindings belong in SubscribeOptions.Bindings,
+// which are applied right after the queue exists.
+type Topology struct {
+	Exchanges        []*ExchangeConfig
+	ExchangeBindings []ExchangeBinding
+}
+
+// equal reports whether t declares the same exchanges and bindings as other, in the same order.
+func (t *Topology) equal(other *Topology) bool {
+	return t == other || reflect.DeepEqual(t, other)
+}
+
+func (t *Topology) declare(ch *amqp.Channel) error {
+	for _, exchange := range t.Exchanges {
+		if err := declareExchange(ch, exchange); err != nil {
+			return err
+		}
+	}
+
+	for _, binding := range t.ExchangeBindings {
+		err := ch.ExchangeBind(binding.Destination, binding.RoutingKey, binding.Source, binding.NoWait, binding.Args)
+		if err != nil {
+			return fmt.Errorf("failed to bind exchange %s to %s: %w", binding.Destination, binding.Source, err)
+		}
+	}
+
+	return nil
+}
+
+func declareExchange(ch *amqp.Channel, exchange *ExchangeConfig) error {
+	err := ch.ExchangeDeclare(
+		exchange.Name,
+		exchange.Kind,
+		exchange.Durable,
+		exchange.AutoDelete,
+		exchange.Internal,
+		exchange.NoWait,
+		exchange.Args,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare exchange %s: %w", exchange.Name, err)
+	}
+	return nil
+}
+
+func bindQueue(ch *amqp.Channel, bindings ...QueueBinding) error {
+	for _, binding := range bindings {
+		err := ch.QueueBind(binding.Queue, binding.RoutingKey, binding.Exchange, binding.NoWait, binding.Args)
+		if err != nil {
+			return fmt.Errorf("failed to bind queue %s to %s: %w", binding.Queue, binding.Exchange, err)
+		}
+	}
+	return nil
+}
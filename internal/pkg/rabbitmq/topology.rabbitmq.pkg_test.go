@@ -0,0 +1,39 @@
+package rabbitmq
+
+import (
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func orderTopology(routingKey string) *Topology {
+	return &Topology{
+		Exchanges: []*ExchangeConfig{
+			DefaultExchangeConfig("orders", ExchangeTopic),
+			DefaultExchangeConfig("events", ExchangeFanout),
+		},
+		ExchangeBindings: []ExchangeBinding{
+			{Destination: "orders", Source: "events", RoutingKey: routingKey, Args: amqp.Table{"x-match": "all"}},
+		},
+	}
+}
+
+func TestTopologyEqual(t *testing.T) {
+	base := orderTopology("order.#")
+
+	if !base.equal(base) {
+		t.Error("topology is not equal to itself")
+	}
+	if !base.equal(orderTopology("order.#")) {
+		t.Error("freshly built topology with the same declarations is not equal")
+	}
+	if base.equal(orderTopology("order.*.created")) {
+		t.Error("topologies with different binding keys are equal")
+	}
+
+	other := orderTopology("order.#")
+	other.Exchanges[0].Kind = ExchangeDirect
+	if base.equal(other) {
+		t.Error("topologies with different exchange kinds are equal")
+	}
+}
@@ -0,0 +1,74 @@
+package rabbitmq
+
+import (
+	"boilerplate-go/internal/pkg/telemetry"
+	"context"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier lets the otel propagator read and write trace context in message headers.
+type headerCarrier amqp.Table
+
+func (c headerCarrier) Get(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func startPublishSpan(ctx context.Context, msg *Message, opts *PublishOptions) (context.Context, trace.Span) {
+	ctx, span := telemetry.Tracer().Start(ctx, "publish "+opts.QueueName,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.destination.name", opts.QueueName),
+			attribute.String("messaging.rabbitmq.exchange", opts.Exchange),
+			attribute.String("messaging.message.id", msg.ID),
+		),
+	)
+
+	if msg.Headers == nil {
+		msg.Headers = amqp.Table{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(msg.Headers))
+
+	return ctx, span
+}
+
+func startConsumeSpan(ctx context.Context, queueName string, msg *amqp.Delivery) (context.Context, trace.Span) {
+	if msg.Headers != nil {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier(msg.Headers))
+	}
+
+	return telemetry.Tracer().Start(ctx, "consume "+queueName,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.destination.name", queueName),
+			attribute.String("messaging.message.id", msg.MessageId),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,264 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	_redis "github.com/redis/go-redis/v9"
+)
+
+func (r *Client) HGet(ctx context.Context, key, field string) (string, error) {
+	result, err := r.client.HGet(ctx, key, field).Result()
+	return result, wrap(err, "get hash field", key+"."+field)
+}
+
+// HSet sets hash fields and returns how many were added.
+func (r *Client) HSet(ctx context.Context, key string, values map[string]interface{}) (int64, error) {
+	result, err := r.client.HSet(ctx, key, values).Result()
+	return result, wrap(err, "set hash", key)
+}
+
+func (r *Client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	result, err := r.client.HGetAll(ctx, key).Result()
+	return result, wrap(err, "get hash", key)
+}
+
+func (r *Client) HDel(ctx context.Context, key string, fields ...string) (int64, error) {
+	result, err := r.client.HDel(ctx, key, fields...).Result()
+	return result, wrap(err, "delete hash fields", key)
+}
+
+func (r *Client) HExists(ctx context.Context, key, field string) (bool, error) {
+	result, err := r.client.HExists(ctx, key, field).Result()
+	return result, wrap(err, "check hash field", key+"."+field)
+}
+
+func (r *Client) HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error) {
+	result, err := r.client.HIncrBy(ctx, key, field, incr).Result()
+	return result, wrap(err, "increment hash field", key+"."+field)
+}
+
+func (r *Client) LPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
+	result, err := r.client.LPush(ctx, key, values...).Result()
+	return result, wrap(err, "push to list", key)
+}
+
+func (r *Client) RPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
+	result, err := r.client.RPush(ctx, key, values...).Result()
+	return result, wrap(err, "push to list", key)
+}
+
+func (r *Client) LPop(ctx context.Context, key string) (string, error) {
+	result, err := r.client.LPop(ctx, key).Result()
+	return result, wrap(err, "pop from list", key)
+}
+
+func (r *Client) RPop(ctx context.Context, key string) (string, error) {
+	result, err := r.client.RPop(ctx, key).Result()
+	return result, wrap(err, "pop from list", key)
+}
+
+func (r *Client) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	result, err := r.client.LRange(ctx, key, start, stop).Result()
+	return result, wrap(err, "read list", key)
+}
+
+func (r *Client) LLen(ctx context.Context, key string) (int64, error) {
+	result, err := r.client.LLen(ctx, key).Result()
+	return result, wrap(err, "read list length", key)
+}
+
+func (r *Client) LTrim(ctx context.Context, key string, start, stop int64) error {
+	return wrap(r.client.LTrim(ctx, key, start, stop).Err(), "trim list", key)
+}
+
+func (r *Client) SAdd(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	result, err := r.client.SAdd(ctx, key, members...).Result()
+	return result, wrap(err, "add to set", key)
+}
+
+func (r *Client) SRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	result, err := r.client.SRem(ctx, key, members...).Result()
+	return result, wrap(err, "remove from set", key)
+}
+
+func (r *Client) SMembers(ctx context.Context, key string) ([]string, error) {
+	result, err := r.client.SMembers(ctx, key).Result()
+	return result, wrap(err, "read set", key)
+}
+
+func (r *Client) SIsMember(ctx context.Context, key string, member interface{}) (bool, error) {
+	result, err := r.client.SIsMember(ctx, key, member).Result()
+	return result, wrap(err, "check set member", key)
+}
+
+func (r *Client) SCard(ctx context.Context, key string) (int64, error) {
+	result, err := r.client.SCard(ctx, key).Result()
+	return result, wrap(err, "read set size", key)
+}
+
+func (r *Client) ZAdd(ctx context.Context, key string, members ...Z) (int64, error) {
+	result, err := r.client.ZAdd(ctx, key, members...).Result()
+	return result, wrap(err, "add to sorted set", key)
+}
+
+func (r *Client) ZRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	result, err := r.client.ZRem(ctx, key, members...).Result()
+	return result, wrap(err, "remove from sorted set", key)
+}
+
+func (r *Client) ZScore(ctx context.Context, key, member string) (float64, error) {
+	result, err := r.client.ZScore(ctx, key, member).Result()
+	return result, wrap(err, "read sorted set score", key)
+}
+
+func (r *Client) ZIncrBy(ctx context.Context, key string, increment float64, member string) (float64, error) {
+	result, err := r.client.ZIncrBy(ctx, key, increment, member).Result()
+	return result, wrap(err, "increment sorted set score", key)
+}
+
+func (r *Client) ZCard(ctx context.Context, key string) (int64, error) {
+	result, err := r.client.ZCard(ctx, key).Result()
+	return result, wrap(err, "read sorted set size", key)
+}
+
+func (r *Client) ZRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	result, err := r.client.ZRange(ctx, key, start, stop).Result()
+	return result, wrap(err, "read sorted set", key)
+}
+
+func (r *Client) ZRangeWithScores(ctx context.Context, key string, start, stop int64) ([]Z, error) {
+	result, err := r.client.ZRangeWithScores(ctx, key, start, stop).Result()
+	return result, wrap(err, "read sorted set", key)
+}
+
+// ZRangeByScore returns the members with min <= score <= max, lowest score first.
+func (r *Client) ZRangeByScore(ctx context.Context, key string, min, max float64) ([]string, error) {
+	result, err := r.client.ZRangeByScore(ctx, key, &_redis.ZRangeBy{
+		Min: formatScore(min),
+		Max: formatScore(max),
+	}).Result()
+	return result, wrap(err, "read sorted set", key)
+}
+
+// ZRemRangeByScore removes the members with min <= score <= max.
+func (r *Client) ZRemRangeByScore(ctx context.Context, key string, min, max float64) (int64, error) {
+	result, err := r.client.ZRemRangeByScore(ctx, key, formatScore(min), formatScore(max)).Result()
+	return result, wrap(err, "remove from sorted set", key)
+}
+
+func formatScore(score float64) string {
+	switch {
+	case math.IsInf(score, 1):
+		return "+inf"
+	case math.IsInf(score, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
+
+// Publish sends message to channel and returns how many subscribers received it.
+func (r *Client) Publish(ctx context.Context, channel string, message interface{}) (int64, error) {
+	result, err := r.client.Publish(ctx, channel, message).Result()
+	return result, wrap(err, "publish to channel", channel)
+}
+
+type clientPubSub struct {
+	pubsub *_redis.PubSub
+}
+
+func (p *clientPubSub) Channel() <-chan *PubSubMessage {
+	return p.pubsub.Channel()
+}
+
+func (p *clientPubSub) Close() error {
+	return p.pubsub.Close()
+}
+
+// Subscribe waits for the subscription to be confirmed. go-redis resubscribes on its own after
+// a reconnect.
+func (r *Client) Subscribe(ctx context.Context, channels ...string) (PubSub, error) {
+	pubsub := r.client.Subscribe(ctx, channels...)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, wrap(err, "subscribe to channels", strings.Join(channels, ","))
+	}
+	return &clientPubSub{pubsub: pubsub}, nil
+}
+
+func (r *Client) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	result, err := r.client.XAdd(ctx, &_redis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: maxLen > 0,
+		Values: values,
+	}).Result()
+	return result, wrap(err, "add to stream", stream)
+}
+
+func (r *Client) XGroupCreate(ctx context.Context, stream, group, start string) error {
+	err := r.client.XGroupCreateMkStream(ctx, stream, group, start).Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return wrap(err, "create group on stream", stream+"."+group)
+}
+
+func (r *Client) XReadGroup(ctx context.Context, group, consumer, stream string, count int64, block time.Duration) ([]XMessage, error) {
+	streams, err := r.client.XReadGroup(ctx, &_redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{stream, ">"},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if errors.Is(err, NilType) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrap(err, "read group from stream", stream+"."+group)
+	}
+
+	var messages []XMessage
+	for _, s := range streams {
+		messages = append(messages, s.Messages...)
+	}
+	return messages, nil
+}
+
+func (r *Client) XAck(ctx context.Context, stream, group string, ids ...string) (int64, error) {
+	result, err := r.client.XAck(ctx, stream, group, ids...).Result()
+	return result, wrap(err, "acknowledge stream entries", stream+"."+group)
+}
+
+func (r *Client) XAutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, start string, count int64) ([]XMessage, string, error) {
+	messages, next, err := r.client.XAutoClaim(ctx, &_redis.XAutoClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  minIdle,
+		Start:    start,
+		Count:    count,
+	}).Result()
+	return messages, next, wrap(err, "claim pending entries of stream", stream+"."+group)
+}
+
+func (r *Client) XPendingExt(ctx context.Context, stream, group, start, end string, count int64) ([]XPendingExt, error) {
+	result, err := r.client.XPendingExt(ctx, &_redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  group,
+		Start:  start,
+		End:    end,
+		Count:  count,
+	}).Result()
+	return result, wrap(err, "list pending entries of stream", stream+"."+group)
+}
+
+func (r *Client) XLen(ctx context.Context, stream string) (int64, error) {
+	result, err := r.client.XLen(ctx, stream).Result()
+	return result, wrap(err, "get length of stream", stream)
+}
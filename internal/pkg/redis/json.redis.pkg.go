@@ -0,0 +1,37 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// IsNil reports whether err means the key or field does not exist.
+func IsNil(err error) bool {
+	return errors.Is(err, NilType)
+}
+
+// SetJSON stores value encoded as JSON.
+func SetJSON(ctx context.Context, r IRedis, key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode key %s: %w", key, err)
+	}
+	return r.Set(ctx, key, data, expiration)
+}
+
+// GetJSON reads a value stored with SetJSON. It returns NilType when the key does not exist.
+func GetJSON[T any](ctx context.Context, r IRedis, key string) (T, error) {
+	var value T
+
+	data, err := r.Get(ctx, key)
+	if err != nil {
+		return value, err
+	}
+	if err = json.Unmarshal([]byte(data), &value); err != nil {
+		return value, fmt.Errorf("failed to decode key %s: %w", key, err)
+	}
+	return value, nil
+}
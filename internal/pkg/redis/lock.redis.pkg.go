@@ -0,0 +1,301 @@
+package redis
+
+import (
+	"boilerplate-go/internal/pkg/logger"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mrand "math/rand/v2"
+	"sync"
+	"time"
+)
+
+var (
+	ErrLockNotAcquired = errors.New("lock not acquired")
+	ErrLockNotHeld     = errors.New("lock not held")
+)
+
+// minLockRetryDelay keeps TryLock from polling redis in a tight loop when RetryDelay is unset.
+const minLockRetryDelay = 10 * time.Millisecond
+
+// The scripts only touch the key while it still holds the owner's token.
+const (
+	releaseLockScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`
+
+	extendLockScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0`
+)
+
+type LockOptions struct {
+	TTL time.Duration
+	// AutoExtend refreshes the TTL every TTL/3 until the lock is released.
+	AutoExtend bool
+	// WaitTimeout bounds how long TryLock waits; 0 waits until the context is done.
+	WaitTimeout   time.Duration
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+}
+
+func DefaultLockOptions() *LockOptions {
+	return &LockOptions{
+		TTL:           30 * time.Second,
+		AutoExtend:    true,
+		WaitTimeout:   0,
+		RetryDelay:    50 * time.Millisecond,
+		MaxRetryDelay: time.Second,
+	}
+}
+
+type Locker struct {
+	redis  IRedis
+	prefix string
+}
+
+// NewLocker returns a locker storing its keys under prefix, e.g. "APP_TENANT:lock:".
+func NewLocker(rds IRedis, prefix string) *Locker {
+	return &Locker{redis: rds, prefix: prefix}
+}
+
+type Lock struct {
+	redis IRedis
+	key   string
+	token string
+	ttl   time.Duration
+
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+	lost chan struct{}
+}
+
+// Acquire takes the lock once and returns ErrLockNotAcquired when another owner holds it. A TTL
+// that is not positive falls back to the default, a lock must always expire.
+func (l *Locker) Acquire(ctx context.Context, name string, opts *LockOptions) (*Lock, error) {
+	if opts == nil {
+		opts = DefaultLockOptions()
+	}
+	if opts.TTL <= 0 {
+		fixed := *opts
+		fixed.TTL = DefaultLockOptions().TTL
+		opts = &fixed
+	}
+
+	token, err := lockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	key := l.prefix + name
+	ok, err := l.redis.SetNX(ctx, key, token, opts.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+	if !ok {
+		return nil, ErrLockNotAcquired
+	}
+
+	lock := &Lock{
+		redis: l.redis,
+		key:   key,
+		token: token,
+		ttl:   opts.TTL,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+		lost:  make(chan struct{}),
+	}
+
+	if opts.AutoExtend {
+		go lock.autoExtend(context.WithoutCancel(ctx))
+	} else {
+		close(lock.done)
+	}
+
+	return lock, nil
+}
+
+// TryLock retries Acquire with exponential backoff until it succeeds, WaitTimeout elapses or ctx
+// is done.
+func (l *Locker) TryLock(ctx context.Context, name string, opts *LockOptions) (*Lock, error) {
+	if opts == nil {
+		opts = DefaultLockOptions()
+	}
+
+	if opts.WaitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.WaitTimeout)
+		defer cancel()
+	}
+
+	delay := max(opts.RetryDelay, minLockRetryDelay)
+	maxDelay := max(opts.MaxRetryDelay, delay)
+	for {
+		lock, err := l.Acquire(ctx, name, opts)
+		if err == nil || !errors.Is(err, ErrLockNotAcquired) {
+			return lock, err
+		}
+
+		timer := time.NewTimer(jitter(delay))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w: %w", ErrLockNotAcquired, ctx.Err())
+		case <-timer.C:
+		}
+
+		delay = min(delay*2, maxDelay)
+	}
+}
+
+// WithLock runs fn while holding the lock. The context passed to fn is cancelled if the lock is
+// lost before fn returns.
+func (l *Locker) WithLock(ctx context.Context, name string, opts *LockOptions, fn func(ctx context.Context) error) error {
+	lock, err := l.TryLock(ctx, name, opts)
+	if err != nil {
+		return err
+	}
+
+	fnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-lock.Lost():
+			cancel()
+		case <-fnCtx.Done():
+		}
+	}()
+
+	fnErr := fn(fnCtx)
+
+	releaseCtx, releaseCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer releaseCancel()
+	if err = lock.Release(releaseCtx); err != nil && fnErr == nil {
+		return err
+	}
+	return fnErr
+}
+
+func (lk *Lock) Key() string {
+	return lk.key
+}
+
+func (lk *Lock) Token() string {
+	return lk.token
+}
+
+// Lost is closed when auto-extension finds the lock expired or taken by another owner.
+func (lk *Lock) Lost() <-chan struct{} {
+	return lk.lost
+}
+
+// Extend resets the TTL of a held lock and returns ErrLockNotHeld when it expired or changed
+// owner.
+func (lk *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	return lk.compareAndRun(ctx, extendLockScript, func(pipe Pipeliner) {
+		pipe.Expire(lk.key, ttl)
+	}, ttl.Milliseconds())
+}
+
+// Release stops auto-extension and deletes the lock if it is still owned by this holder.
+func (lk *Lock) Release(ctx context.Context) error {
+	lk.once.Do(func() { close(lk.stop) })
+	<-lk.done
+
+	return lk.compareAndRun(ctx, releaseLockScript, func(pipe Pipeliner) {
+		pipe.Del(lk.key)
+	})
+}
+
+// compareAndRun runs script when the key still holds the token. Clients without lua support,
+// such as Memory, get the same guarantee from WATCH.
+func (lk *Lock) compareAndRun(ctx context.Context, script string, fallback func(pipe Pipeliner), args ...interface{}) error {
+	result, err := lk.redis.Eval(ctx, script, []string{lk.key}, append([]interface{}{lk.token}, args...)...)
+	if errors.Is(err, ErrNotSupported) {
+		return lk.compareAndRunWatch(ctx, fallback)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update lock %s: %w", lk.key, err)
+	}
+	if n, ok := result.(int64); !ok || n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+func (lk *Lock) compareAndRunWatch(ctx context.Context, fn func(pipe Pipeliner)) error {
+	err := lk.redis.Watch(ctx, func(tx Tx) error {
+		token, err := tx.Get(ctx, lk.key)
+		if IsNil(err) || (err == nil && token != lk.token) {
+			return ErrLockNotHeld
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.TxPipelined(ctx, func(pipe Pipeliner) error {
+			fn(pipe)
+			return nil
+		})
+	}, lk.key)
+	if errors.Is(err, ErrTxFailed) {
+		return ErrLockNotHeld
+	}
+	return err
+}
+
+// autoExtend logs through ctx, which keeps the values of the Acquire context but is never
+// cancelled; the lock outlives the call that took it.
+func (lk *Lock) autoExtend(ctx context.Context) {
+	defer close(lk.done)
+
+	interval := max(lk.ttl/3, time.Millisecond)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastExtended := time.Now()
+	for {
+		select {
+		case <-lk.stop:
+			return
+		case <-ticker.C:
+			extendCtx, cancel := context.WithTimeout(ctx, interval)
+			err := lk.Extend(extendCtx, lk.ttl)
+			cancel()
+
+			switch {
+			case err == nil:
+				lastExtended = time.Now()
+			case errors.Is(err, ErrLockNotHeld) || time.Since(lastExtended) >= lk.ttl:
+				logger.FromContext(ctx).Warn("lock lost", "key", lk.key, "error", err)
+				close(lk.lost)
+				return
+			default:
+				logger.FromContext(ctx).Warn("failed to extend lock", "key", lk.key, "error", err)
+			}
+		}
+	}
+}
+
+func lockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// jitter spreads retries of competing holders over [d/2, d).
+func jitter(d time.Duration) time.Duration {
+	if d < 2 {
+		return d
+	}
+	return d/2 + mrand.N(d/2)
+}
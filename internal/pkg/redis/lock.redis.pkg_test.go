@@ -0,0 +1,320 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestLocker() (*Locker, *Memory, *testClock) {
+	clock := &testClock{now: time.Now()}
+	m := NewMemory()
+	m.SetClock(clock.Now)
+	return NewLocker(m, "test:lock:"), m, clock
+}
+
+func manualLockOptions(ttl time.Duration) *LockOptions {
+	opts := DefaultLockOptions()
+	opts.TTL = ttl
+	opts.AutoExtend = false
+	return opts
+}
+
+func TestLockSecondHolderIsRejected(t *testing.T) {
+	locker, _, _ := newTestLocker()
+	ctx := context.Background()
+
+	first, err := locker.Acquire(ctx, "job", manualLockOptions(time.Minute))
+	if err != nil {
+		t.Fatalf("first Acquire: %v", err)
+	}
+
+	if _, err = locker.Acquire(ctx, "job", manualLockOptions(time.Minute)); !errors.Is(err, ErrLockNotAcquired) {
+		t.Fatalf("second Acquire err = %v, want ErrLockNotAcquired", err)
+	}
+
+	if err = first.Release(ctx); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	second, err := locker.Acquire(ctx, "job", manualLockOptions(time.Minute))
+	if err != nil {
+		t.Fatalf("Acquire after release: %v", err)
+	}
+	_ = second.Release(ctx)
+}
+
+func TestLockReleaseOnlyByOwner(t *testing.T) {
+	locker, m, clock := newTestLocker()
+	ctx := context.Background()
+
+	first, err := locker.Acquire(ctx, "job", manualLockOptions(time.Second))
+	if err != nil {
+		t.Fatalf("first Acquire: %v", err)
+	}
+
+	clock.Advance(2 * time.Second)
+	second, err := locker.Acquire(ctx, "job", manualLockOptions(time.Minute))
+	if err != nil {
+		t.Fatalf("Acquire after expiry: %v", err)
+	}
+
+	if err = first.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("stale Release err = %v, want ErrLockNotHeld", err)
+	}
+	token, err := m.Get(ctx, second.Key())
+	if err != nil || token != second.Token() {
+		t.Fatalf("lock value = %q, %v, want the second owner's token", token, err)
+	}
+
+	if err = second.Release(ctx); err != nil {
+		t.Fatalf("owner Release: %v", err)
+	}
+	if _, err = m.Get(ctx, second.Key()); !IsNil(err) {
+		t.Fatalf("lock key still present after Release: %v", err)
+	}
+}
+
+func TestLockExtend(t *testing.T) {
+	locker, m, clock := newTestLocker()
+	ctx := context.Background()
+
+	lock, err := locker.Acquire(ctx, "job", manualLockOptions(time.Second))
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	if err = lock.Extend(ctx, time.Minute); err != nil {
+		t.Fatalf("Extend while held: %v", err)
+	}
+	if ttl, _ := m.TTL(ctx, lock.Key()); ttl != time.Minute {
+		t.Fatalf("ttl after Extend = %v, want 1m", ttl)
+	}
+
+	clock.Advance(2 * time.Minute)
+	if err = lock.Extend(ctx, time.Minute); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("Extend after expiry err = %v, want ErrLockNotHeld", err)
+	}
+}
+
+func TestLockNonPositiveTTLExpires(t *testing.T) {
+	locker, m, _ := newTestLocker()
+	ctx := context.Background()
+
+	lock, err := locker.Acquire(ctx, "job", &LockOptions{AutoExtend: false})
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if ttl, _ := m.TTL(ctx, lock.Key()); ttl <= 0 {
+		t.Fatalf("ttl = %v, want the default ttl", ttl)
+	}
+	_ = lock.Release(ctx)
+}
+
+func TestTryLockTimeout(t *testing.T) {
+	locker, _, _ := newTestLocker()
+	ctx := context.Background()
+
+	holder, err := locker.Acquire(ctx, "job", manualLockOptions(time.Minute))
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer func() { _ = holder.Release(ctx) }()
+
+	opts := manualLockOptions(time.Minute)
+	opts.WaitTimeout = 50 * time.Millisecond
+	opts.RetryDelay = 0
+
+	start := time.Now()
+	_, err = locker.TryLock(ctx, "job", opts)
+	if !errors.Is(err, ErrLockNotAcquired) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("TryLock err = %v, want ErrLockNotAcquired and DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed < opts.WaitTimeout {
+		t.Fatalf("TryLock returned after %v, before WaitTimeout", elapsed)
+	}
+}
+
+func TestTryLockCancel(t *testing.T) {
+	locker, _, _ := newTestLocker()
+
+	holder, err := locker.Acquire(context.Background(), "job", manualLockOptions(time.Minute))
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer func() { _ = holder.Release(context.Background()) }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err = locker.TryLock(ctx, "job", manualLockOptions(time.Minute))
+	if !errors.Is(err, ErrLockNotAcquired) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("TryLock err = %v, want ErrLockNotAcquired and Canceled", err)
+	}
+}
+
+func TestWithLockContention(t *testing.T) {
+	locker := NewLocker(NewMemory(), "test:lock:")
+
+	var holders, maxHolders, runs atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			opts := manualLockOptions(time.Minute)
+			opts.RetryDelay = time.Millisecond
+			opts.MaxRetryDelay = 5 * time.Millisecond
+			err := locker.WithLock(context.Background(), "job", opts, func(ctx context.Context) error {
+				current := holders.Add(1)
+				for {
+					seen := maxHolders.Load()
+					if current <= seen || maxHolders.CompareAndSwap(seen, current) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				holders.Add(-1)
+				runs.Add(1)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("WithLock: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if runs.Load() != 20 {
+		t.Fatalf("runs = %d, want 20", runs.Load())
+	}
+	if maxHolders.Load() != 1 {
+		t.Fatalf("max concurrent holders = %d, want 1", maxHolders.Load())
+	}
+}
+
+func autoExtendOptions(ttl time.Duration) *LockOptions {
+	opts := DefaultLockOptions()
+	opts.TTL = ttl
+	opts.AutoExtend = true
+	return opts
+}
+
+func waitLost(t *testing.T, lock *Lock) {
+	t.Helper()
+	select {
+	case <-lock.Lost():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Lost() was not closed")
+	}
+}
+
+func TestLockAutoExtend(t *testing.T) {
+	// The real clock lets the key expire unless it is extended.
+	m := NewMemory()
+	locker := NewLocker(m, "test:lock:")
+	ctx := context.Background()
+
+	lock, err := locker.Acquire(ctx, "job", autoExtendOptions(60*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	// Without extension the key would have expired several times over.
+	time.Sleep(200 * time.Millisecond)
+	select {
+	case <-lock.Lost():
+		t.Fatal("lock lost while auto-extension was running")
+	default:
+	}
+	if token, err := m.Get(ctx, lock.Key()); err != nil || token != lock.Token() {
+		t.Fatalf("key = %q, %v, want the owner's token", token, err)
+	}
+
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if _, err := m.Get(ctx, lock.Key()); !IsNil(err) {
+		t.Fatalf("key still present after Release: %v", err)
+	}
+}
+
+func TestLockLostWhenExpired(t *testing.T) {
+	locker, _, clock := newTestLocker()
+	ctx := context.Background()
+
+	lock, err := locker.Acquire(ctx, "job", autoExtendOptions(30*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	clock.Advance(time.Minute)
+	waitLost(t, lock)
+
+	if err := lock.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("Release of a lost lock = %v, want ErrLockNotHeld", err)
+	}
+}
+
+func TestLockLostWhenStolen(t *testing.T) {
+	locker, m, _ := newTestLocker()
+	ctx := context.Background()
+
+	lock, err := locker.Acquire(ctx, "job", autoExtendOptions(30*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	if err := m.Set(ctx, lock.Key(), "other-owner", time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	waitLost(t, lock)
+
+	if err := lock.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("Release = %v, want ErrLockNotHeld", err)
+	}
+	if token, _ := m.Get(ctx, lock.Key()); token != "other-owner" {
+		t.Fatalf("key = %q, the new owner's lock must be kept", token)
+	}
+}
+
+func TestWithLockCancelsWhenLost(t *testing.T) {
+	locker, m, _ := newTestLocker()
+
+	err := locker.WithLock(context.Background(), "job", autoExtendOptions(30*time.Millisecond), func(ctx context.Context) error {
+		if err := m.Set(ctx, "test:lock:job", "other-owner", time.Minute); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+			t.Error("fn context was not cancelled after the lock was lost")
+			return nil
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("WithLock = %v, want the cancellation seen by fn", err)
+	}
+}
@@ -0,0 +1,233 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	_redis "github.com/redis/go-redis/v9"
+)
+
+// memoryPipeline queues operations and runs them under a single lock when executed.
+type memoryPipeline struct {
+	ctx context.Context
+	m   *Memory
+	ops []func() error
+}
+
+func (p *memoryPipeline) queue(op func() error) {
+	p.ops = append(p.ops, op)
+}
+
+// exec runs the queued operations; the caller holds the lock. Like go-redis it returns the first
+// command error other than NilType.
+func (p *memoryPipeline) exec() error {
+	var first error
+	for _, op := range p.ops {
+		if err := op(); err != nil && first == nil && !IsNil(err) {
+			first = err
+		}
+	}
+	return first
+}
+
+func (p *memoryPipeline) Get(key string) *StringCmd {
+	cmd := _redis.NewStringCmd(p.ctx, "get", key)
+	p.queue(func() error {
+		value, err := p.m.get(key)
+		cmd.SetVal(value)
+		cmd.SetErr(err)
+		return err
+	})
+	return cmd
+}
+
+func (p *memoryPipeline) Set(key string, value interface{}, expiration time.Duration) *StatusCmd {
+	cmd := _redis.NewStatusCmd(p.ctx, "set", key, value)
+	p.queue(func() error {
+		_, err := p.m.set(key, value, expiration, false)
+		if err == nil {
+			cmd.SetVal("OK")
+		}
+		cmd.SetErr(err)
+		return err
+	})
+	return cmd
+}
+
+func (p *memoryPipeline) SetNX(key string, value interface{}, expiration time.Duration) *BoolCmd {
+	cmd := _redis.NewBoolCmd(p.ctx, "set", key, value, "nx")
+	p.queue(func() error {
+		ok, err := p.m.set(key, value, expiration, true)
+		cmd.SetVal(ok)
+		cmd.SetErr(err)
+		return err
+	})
+	return cmd
+}
+
+func (p *memoryPipeline) Del(keys ...string) *IntCmd {
+	cmd := _redis.NewIntCmd(p.ctx, "del")
+	p.queue(func() error {
+		cmd.SetVal(p.m.del(keys...))
+		return nil
+	})
+	return cmd
+}
+
+func (p *memoryPipeline) Exists(keys ...string) *IntCmd {
+	cmd := _redis.NewIntCmd(p.ctx, "exists")
+	p.queue(func() error {
+		cmd.SetVal(p.m.exists(keys...))
+		return nil
+	})
+	return cmd
+}
+
+func (p *memoryPipeline) Expire(key string, expiration time.Duration) *BoolCmd {
+	cmd := _redis.NewBoolCmd(p.ctx, "expire", key)
+	p.queue(func() error {
+		cmd.SetVal(p.m.expire(key, expiration))
+		return nil
+	})
+	return cmd
+}
+
+func (p *memoryPipeline) Incr(key string) *IntCmd {
+	return p.IncrBy(key, 1)
+}
+
+func (p *memoryPipeline) IncrBy(key string, value int64) *IntCmd {
+	cmd := _redis.NewIntCmd(p.ctx, "incrby", key, value)
+	p.queue(func() error {
+		result, err := p.m.incrBy(key, value)
+		cmd.SetVal(result)
+		cmd.SetErr(err)
+		return err
+	})
+	return cmd
+}
+
+func (p *memoryPipeline) HSet(key string, values map[string]interface{}) *IntCmd {
+	return p.intOp("hset", key, func() (int64, error) { return p.m.hset(key, values) })
+}
+
+func (p *memoryPipeline) HGet(key, field string) *StringCmd {
+	cmd := _redis.NewStringCmd(p.ctx, "hget", key, field)
+	p.queue(func() error {
+		value, err := p.m.hget(key, field)
+		cmd.SetVal(value)
+		cmd.SetErr(err)
+		return err
+	})
+	return cmd
+}
+
+func (p *memoryPipeline) HDel(key string, fields ...string) *IntCmd {
+	return p.intOp("hdel", key, func() (int64, error) { return p.m.hdel(key, fields...) })
+}
+
+func (p *memoryPipeline) HIncrBy(key, field string, incr int64) *IntCmd {
+	return p.intOp("hincrby", key, func() (int64, error) { return p.m.hincrBy(key, field, incr) })
+}
+
+func (p *memoryPipeline) LPush(key string, values ...interface{}) *IntCmd {
+	return p.intOp("lpush", key, func() (int64, error) { return p.m.push(key, true, values...) })
+}
+
+func (p *memoryPipeline) RPush(key string, values ...interface{}) *IntCmd {
+	return p.intOp("rpush", key, func() (int64, error) { return p.m.push(key, false, values...) })
+}
+
+func (p *memoryPipeline) SAdd(key string, members ...interface{}) *IntCmd {
+	return p.intOp("sadd", key, func() (int64, error) { return p.m.sadd(key, members...) })
+}
+
+func (p *memoryPipeline) SRem(key string, members ...interface{}) *IntCmd {
+	return p.intOp("srem", key, func() (int64, error) { return p.m.srem(key, members...) })
+}
+
+func (p *memoryPipeline) SCard(key string) *IntCmd {
+	return p.intOp("scard", key, func() (int64, error) { return p.m.scard(key) })
+}
+
+func (p *memoryPipeline) ZAdd(key string, members ...Z) *IntCmd {
+	return p.intOp("zadd", key, func() (int64, error) { return p.m.zadd(key, members...) })
+}
+
+func (p *memoryPipeline) ZRem(key string, members ...interface{}) *IntCmd {
+	return p.intOp("zrem", key, func() (int64, error) { return p.m.zrem(key, members...) })
+}
+
+func (p *memoryPipeline) intOp(name, key string, op func() (int64, error)) *IntCmd {
+	cmd := _redis.NewIntCmd(p.ctx, name, key)
+	p.queue(func() error {
+		result, err := op()
+		cmd.SetVal(result)
+		cmd.SetErr(err)
+		return err
+	})
+	return cmd
+}
+
+func (m *Memory) Pipelined(ctx context.Context, fn func(pipe Pipeliner) error) error {
+	pipe := &memoryPipeline{ctx: ctx, m: m}
+	if err := fn(pipe); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return pipe.exec()
+}
+
+// TxPipelined behaves like Pipelined: the memory pipeline already runs atomically.
+func (m *Memory) TxPipelined(ctx context.Context, fn func(pipe Pipeliner) error) error {
+	return m.Pipelined(ctx, fn)
+}
+
+type memoryTx struct {
+	m       *Memory
+	watched map[string]uint64
+}
+
+func (t *memoryTx) Get(ctx context.Context, key string) (string, error) {
+	return t.m.Get(ctx, key)
+}
+
+func (t *memoryTx) HGet(ctx context.Context, key, field string) (string, error) {
+	return t.m.HGet(ctx, key, field)
+}
+
+func (t *memoryTx) Exists(ctx context.Context, keys ...string) (int64, error) {
+	return t.m.Exists(ctx, keys...)
+}
+
+func (t *memoryTx) TxPipelined(ctx context.Context, fn func(pipe Pipeliner) error) error {
+	pipe := &memoryPipeline{ctx: ctx, m: t.m}
+	if err := fn(pipe); err != nil {
+		return err
+	}
+
+	t.m.mu.Lock()
+	defer t.m.mu.Unlock()
+
+	for key, version := range t.watched {
+		t.m.lookup(key)
+		if t.m.versions[key] != version {
+			return ErrTxFailed
+		}
+	}
+	return pipe.exec()
+}
+
+func (m *Memory) Watch(ctx context.Context, fn func(tx Tx) error, keys ...string) error {
+	m.mu.Lock()
+	watched := make(map[string]uint64, len(keys))
+	for _, key := range keys {
+		m.lookup(key)
+		watched[key] = m.versions[key]
+	}
+	m.mu.Unlock()
+
+	return fn(&memoryTx{m: m, watched: watched})
+}
@@ -0,0 +1,104 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryPubSubBuffer matches the channel size used by go-redis. Like there, messages for a
+// subscriber that stops reading are dropped.
+const memoryPubSubBuffer = 100
+
+type memoryPubSub struct {
+	m        *Memory
+	channels []string
+	ch       chan *PubSubMessage
+	once     sync.Once
+}
+
+func (p *memoryPubSub) Channel() <-chan *PubSubMessage {
+	return p.ch
+}
+
+func (p *memoryPubSub) Close() error {
+	p.once.Do(func() {
+		p.m.mu.Lock()
+		defer p.m.mu.Unlock()
+
+		for _, channel := range p.channels {
+			delete(p.m.subscribers[channel], p)
+			if len(p.m.subscribers[channel]) == 0 {
+				delete(p.m.subscribers, channel)
+			}
+		}
+		close(p.ch)
+	})
+	return nil
+}
+
+func (m *Memory) Publish(_ context.Context, channel string, message interface{}) (int64, error) {
+	payload, err := memoryValue(message)
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var received int64
+	for sub := range m.subscribers[channel] {
+		select {
+		case sub.ch <- &PubSubMessage{Channel: channel, Payload: payload}:
+			received++
+		default:
+		}
+	}
+	return received, nil
+}
+
+func (m *Memory) Subscribe(ctx context.Context, channels ...string) (PubSub, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub := &memoryPubSub{m: m, channels: channels, ch: make(chan *PubSubMessage, memoryPubSubBuffer)}
+	for _, channel := range channels {
+		if m.subscribers[channel] == nil {
+			m.subscribers[channel] = make(map[*memoryPubSub]struct{})
+		}
+		m.subscribers[channel][sub] = struct{}{}
+	}
+	return sub, nil
+}
+
+func (m *Memory) XAdd(context.Context, string, int64, map[string]interface{}) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (m *Memory) XGroupCreate(context.Context, string, string, string) error {
+	return ErrNotSupported
+}
+
+func (m *Memory) XReadGroup(context.Context, string, string, string, int64, time.Duration) ([]XMessage, error) {
+	return nil, ErrNotSupported
+}
+
+func (m *Memory) XAck(context.Context, string, string, ...string) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+func (m *Memory) XAutoClaim(context.Context, string, string, string, time.Duration, string, int64) ([]XMessage, string, error) {
+	return nil, "", ErrNotSupported
+}
+
+func (m *Memory) XPendingExt(context.Context, string, string, string, string, int64) ([]XPendingExt, error) {
+	return nil, ErrNotSupported
+}
+
+func (m *Memory) XLen(context.Context, string) (int64, error) {
+	return 0, ErrNotSupported
+}
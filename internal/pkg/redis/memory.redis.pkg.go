@@ -0,0 +1,941 @@
+package redis
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrWrongType    = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	ErrNotInteger   = errors.New("ERR value is not an integer or out of range")
+	ErrNotSupported = errors.New("command is not supported by the in-memory redis")
+)
+
+const (
+	kindString = "string"
+	kindHash   = "hash"
+	kindList   = "list"
+	kindSet    = "set"
+	kindZSet   = "zset"
+)
+
+// Memory is an in-process IRedis meant for unit tests. It follows redis semantics for missing
+// keys, expirations and wrong types, but Eval and the stream commands return ErrNotSupported.
+type Memory struct {
+	mu          sync.Mutex
+	data        map[string]*memoryEntry
+	versions    map[string]uint64
+	subscribers map[string]map[*memoryPubSub]struct{}
+	now         func() time.Time
+}
+
+type memoryEntry struct {
+	kind     string
+	str      string
+	hash     map[string]string
+	list     []string
+	set      map[string]struct{}
+	zset     map[string]float64
+	expireAt time.Time
+}
+
+var _ IRedis = (*Memory)(nil)
+
+func NewMemory() *Memory {
+	return &Memory{
+		data:        make(map[string]*memoryEntry),
+		versions:    make(map[string]uint64),
+		subscribers: make(map[string]map[*memoryPubSub]struct{}),
+		now:         time.Now,
+	}
+}
+
+// SetClock replaces the clock used for expirations.
+func (m *Memory) SetClock(now func() time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = now
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
+
+func (m *Memory) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// touch marks key as modified for Watch.
+func (m *Memory) touch(key string) {
+	m.versions[key]++
+}
+
+// lookup returns the live entry for key, evicting it when expired.
+func (m *Memory) lookup(key string) *memoryEntry {
+	e, ok := m.data[key]
+	if !ok {
+		return nil
+	}
+	if !e.expireAt.IsZero() && !m.now().Before(e.expireAt) {
+		delete(m.data, key)
+		m.touch(key)
+		return nil
+	}
+	return e
+}
+
+// read returns the entry for key when it holds kind, nil when the key does not exist.
+func (m *Memory) read(key, kind string) (*memoryEntry, error) {
+	e := m.lookup(key)
+	if e != nil && e.kind != kind {
+		return nil, ErrWrongType
+	}
+	return e, nil
+}
+
+// write returns the entry for key, creating it with kind when missing.
+func (m *Memory) write(key, kind string) (*memoryEntry, error) {
+	e, err := m.read(key, kind)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		e = &memoryEntry{kind: kind}
+		switch kind {
+		case kindHash:
+			e.hash = make(map[string]string)
+		case kindSet:
+			e.set = make(map[string]struct{})
+		case kindZSet:
+			e.zset = make(map[string]float64)
+		}
+		m.data[key] = e
+	}
+	m.touch(key)
+	return e, nil
+}
+
+// prune removes container keys that became empty, as redis does.
+func (m *Memory) prune(key string, e *memoryEntry) {
+	if len(e.hash) == 0 && len(e.list) == 0 && len(e.set) == 0 && len(e.zset) == 0 && e.kind != kindString {
+		delete(m.data, key)
+	}
+}
+
+// memoryValue converts a value the way go-redis writes command arguments.
+func memoryValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return strconv.FormatInt(v.Nanoseconds(), 10), nil
+	case encoding.BinaryMarshaler:
+		data, err := v.MarshalBinary()
+		return string(data), err
+	default:
+		return "", fmt.Errorf("redis: can't marshal %T (implement encoding.BinaryMarshaler)", value)
+	}
+}
+
+func (m *Memory) get(key string) (string, error) {
+	e, err := m.read(key, kindString)
+	if err != nil {
+		return "", err
+	}
+	if e == nil {
+		return "", NilType
+	}
+	return e.str, nil
+}
+
+func (m *Memory) set(key string, value interface{}, expiration time.Duration, onlyIfMissing bool) (bool, error) {
+	str, err := memoryValue(value)
+	if err != nil {
+		return false, err
+	}
+
+	existing := m.lookup(key)
+	if onlyIfMissing && existing != nil {
+		return false, nil
+	}
+
+	e := &memoryEntry{kind: kindString, str: str}
+	switch {
+	case expiration > 0:
+		e.expireAt = m.now().Add(expiration)
+	case expiration == -1 && existing != nil:
+		e.expireAt = existing.expireAt
+	}
+	m.data[key] = e
+	m.touch(key)
+	return true, nil
+}
+
+func (m *Memory) incrBy(key string, value int64) (int64, error) {
+	e, err := m.read(key, kindString)
+	if err != nil {
+		return 0, err
+	}
+
+	var current int64
+	if e != nil {
+		if current, err = strconv.ParseInt(e.str, 10, 64); err != nil {
+			return 0, ErrNotInteger
+		}
+	} else {
+		e = &memoryEntry{kind: kindString}
+		m.data[key] = e
+	}
+
+	current += value
+	e.str = strconv.FormatInt(current, 10)
+	m.touch(key)
+	return current, nil
+}
+
+func (m *Memory) del(keys ...string) int64 {
+	var deleted int64
+	for _, key := range keys {
+		if m.lookup(key) != nil {
+			delete(m.data, key)
+			m.touch(key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+func (m *Memory) expire(key string, expiration time.Duration) bool {
+	e := m.lookup(key)
+	if e == nil {
+		return false
+	}
+	if expiration <= 0 {
+		delete(m.data, key)
+	} else {
+		e.expireAt = m.now().Add(expiration)
+	}
+	m.touch(key)
+	return true
+}
+
+func (m *Memory) Get(_ context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.get(key)
+}
+
+func (m *Memory) Set(_ context.Context, key string, value interface{}, expiration time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := m.set(key, value, expiration, false)
+	return err
+}
+
+func (m *Memory) SetNX(_ context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.set(key, value, expiration, true)
+}
+
+func (m *Memory) MGet(_ context.Context, keys ...string) ([]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]interface{}, len(keys))
+	for i, key := range keys {
+		if value, err := m.get(key); err == nil {
+			result[i] = value
+		}
+	}
+	return result, nil
+}
+
+func (m *Memory) MSet(_ context.Context, values map[string]interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, value := range values {
+		if _, err := memoryValue(value); err != nil {
+			return err
+		}
+	}
+	for key, value := range values {
+		if _, err := m.set(key, value, 0, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Incr(ctx context.Context, key string) (int64, error) {
+	return m.IncrBy(ctx, key, 1)
+}
+
+func (m *Memory) IncrBy(_ context.Context, key string, value int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.incrBy(key, value)
+}
+
+func (m *Memory) Decr(ctx context.Context, key string) (int64, error) {
+	return m.IncrBy(ctx, key, -1)
+}
+
+func (m *Memory) DecrBy(ctx context.Context, key string, value int64) (int64, error) {
+	return m.IncrBy(ctx, key, -value)
+}
+
+func (m *Memory) Del(_ context.Context, keys ...string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.del(keys...), nil
+}
+
+func (m *Memory) Exists(_ context.Context, keys ...string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.exists(keys...), nil
+}
+
+func (m *Memory) exists(keys ...string) int64 {
+	var count int64
+	for _, key := range keys {
+		if m.lookup(key) != nil {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *Memory) Expire(_ context.Context, key string, expiration time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.expire(key, expiration), nil
+}
+
+func (m *Memory) TTL(_ context.Context, key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.lookup(key)
+	switch {
+	case e == nil:
+		return -2, nil
+	case e.expireAt.IsZero():
+		return -1, nil
+	default:
+		return e.expireAt.Sub(m.now()).Round(time.Second), nil
+	}
+}
+
+func (m *Memory) Scan(_ context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if match == "" {
+		match = "*"
+	}
+	if count <= 0 {
+		count = 10
+	}
+
+	all := make([]string, 0, len(m.data))
+	for key := range m.data {
+		if m.lookup(key) != nil {
+			all = append(all, key)
+		}
+	}
+	sort.Strings(all)
+
+	var keys []string
+	end := min(cursor+uint64(count), uint64(len(all)))
+	for i := cursor; i < end; i++ {
+		if ok, _ := path.Match(match, all[i]); ok {
+			keys = append(keys, all[i])
+		}
+	}
+
+	if end >= uint64(len(all)) {
+		return keys, 0, nil
+	}
+	return keys, end, nil
+}
+
+func (m *Memory) Eval(context.Context, string, []string, ...interface{}) (interface{}, error) {
+	return nil, ErrNotSupported
+}
+
+func (m *Memory) hget(key, field string) (string, error) {
+	e, err := m.read(key, kindHash)
+	if err != nil {
+		return "", err
+	}
+	if e == nil {
+		return "", NilType
+	}
+	value, ok := e.hash[field]
+	if !ok {
+		return "", NilType
+	}
+	return value, nil
+}
+
+func (m *Memory) hset(key string, values map[string]interface{}) (int64, error) {
+	converted := make(map[string]string, len(values))
+	for field, value := range values {
+		str, err := memoryValue(value)
+		if err != nil {
+			return 0, err
+		}
+		converted[field] = str
+	}
+
+	e, err := m.write(key, kindHash)
+	if err != nil {
+		return 0, err
+	}
+
+	var added int64
+	for field, value := range converted {
+		if _, ok := e.hash[field]; !ok {
+			added++
+		}
+		e.hash[field] = value
+	}
+	m.prune(key, e)
+	return added, nil
+}
+
+func (m *Memory) hdel(key string, fields ...string) (int64, error) {
+	e, err := m.read(key, kindHash)
+	if err != nil || e == nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, field := range fields {
+		if _, ok := e.hash[field]; ok {
+			delete(e.hash, field)
+			deleted++
+		}
+	}
+	if deleted > 0 {
+		m.touch(key)
+		m.prune(key, e)
+	}
+	return deleted, nil
+}
+
+func (m *Memory) hincrBy(key, field string, incr int64) (int64, error) {
+	e, err := m.write(key, kindHash)
+	if err != nil {
+		return 0, err
+	}
+
+	var current int64
+	if value, ok := e.hash[field]; ok {
+		if current, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return 0, ErrNotInteger
+		}
+	}
+	current += incr
+	e.hash[field] = strconv.FormatInt(current, 10)
+	return current, nil
+}
+
+func (m *Memory) HGet(_ context.Context, key, field string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.hget(key, field)
+}
+
+func (m *Memory) HSet(_ context.Context, key string, values map[string]interface{}) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.hset(key, values)
+}
+
+func (m *Memory) HGetAll(_ context.Context, key string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.read(key, kindHash)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	if e != nil {
+		for field, value := range e.hash {
+			result[field] = value
+		}
+	}
+	return result, nil
+}
+
+func (m *Memory) HDel(_ context.Context, key string, fields ...string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.hdel(key, fields...)
+}
+
+func (m *Memory) HExists(_ context.Context, key, field string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.hget(key, field)
+	if IsNil(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (m *Memory) HIncrBy(_ context.Context, key, field string, incr int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.hincrBy(key, field, incr)
+}
+
+func (m *Memory) push(key string, left bool, values ...interface{}) (int64, error) {
+	converted := make([]string, 0, len(values))
+	for _, value := range values {
+		str, err := memoryValue(value)
+		if err != nil {
+			return 0, err
+		}
+		converted = append(converted, str)
+	}
+
+	e, err := m.write(key, kindList)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, value := range converted {
+		if left {
+			e.list = append([]string{value}, e.list...)
+		} else {
+			e.list = append(e.list, value)
+		}
+	}
+	m.prune(key, e)
+	return int64(len(e.list)), nil
+}
+
+func (m *Memory) pop(key string, left bool) (string, error) {
+	e, err := m.read(key, kindList)
+	if err != nil {
+		return "", err
+	}
+	if e == nil {
+		return "", NilType
+	}
+
+	var value string
+	if left {
+		value, e.list = e.list[0], e.list[1:]
+	} else {
+		value, e.list = e.list[len(e.list)-1], e.list[:len(e.list)-1]
+	}
+	m.touch(key)
+	m.prune(key, e)
+	return value, nil
+}
+
+// listRange converts redis start/stop indexes, which may be negative, into slice bounds.
+func listRange(length int, start, stop int64) (int, int) {
+	n := int64(length)
+	if start < 0 {
+		start = max(n+start, 0)
+	}
+	if stop < 0 {
+		stop = n + stop
+	}
+	stop = min(stop, n-1)
+	if start > stop || start >= n {
+		return 0, 0
+	}
+	return int(start), int(stop) + 1
+}
+
+func (m *Memory) LPush(_ context.Context, key string, values ...interface{}) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.push(key, true, values...)
+}
+
+func (m *Memory) RPush(_ context.Context, key string, values ...interface{}) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.push(key, false, values...)
+}
+
+func (m *Memory) LPop(_ context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pop(key, true)
+}
+
+func (m *Memory) RPop(_ context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pop(key, false)
+}
+
+func (m *Memory) LRange(_ context.Context, key string, start, stop int64) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.read(key, kindList)
+	if err != nil || e == nil {
+		return []string{}, err
+	}
+	from, to := listRange(len(e.list), start, stop)
+	return append([]string{}, e.list[from:to]...), nil
+}
+
+func (m *Memory) LLen(_ context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.read(key, kindList)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	return int64(len(e.list)), nil
+}
+
+func (m *Memory) LTrim(_ context.Context, key string, start, stop int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.read(key, kindList)
+	if err != nil || e == nil {
+		return err
+	}
+	from, to := listRange(len(e.list), start, stop)
+	e.list = append([]string{}, e.list[from:to]...)
+	m.touch(key)
+	m.prune(key, e)
+	return nil
+}
+
+func (m *Memory) sadd(key string, members ...interface{}) (int64, error) {
+	converted := make([]string, 0, len(members))
+	for _, member := range members {
+		str, err := memoryValue(member)
+		if err != nil {
+			return 0, err
+		}
+		converted = append(converted, str)
+	}
+
+	e, err := m.write(key, kindSet)
+	if err != nil {
+		return 0, err
+	}
+
+	var added int64
+	for _, member := range converted {
+		if _, ok := e.set[member]; !ok {
+			e.set[member] = struct{}{}
+			added++
+		}
+	}
+	m.prune(key, e)
+	return added, nil
+}
+
+func (m *Memory) srem(key string, members ...interface{}) (int64, error) {
+	e, err := m.read(key, kindSet)
+	if err != nil || e == nil {
+		return 0, err
+	}
+
+	var removed int64
+	for _, member := range members {
+		str, err := memoryValue(member)
+		if err != nil {
+			return removed, err
+		}
+		if _, ok := e.set[str]; ok {
+			delete(e.set, str)
+			removed++
+		}
+	}
+	if removed > 0 {
+		m.touch(key)
+		m.prune(key, e)
+	}
+	return removed, nil
+}
+
+func (m *Memory) SAdd(_ context.Context, key string, members ...interface{}) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sadd(key, members...)
+}
+
+func (m *Memory) SRem(_ context.Context, key string, members ...interface{}) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.srem(key, members...)
+}
+
+// SMembers returns the members sorted, so tests can compare them directly.
+func (m *Memory) SMembers(_ context.Context, key string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.read(key, kindSet)
+	if err != nil || e == nil {
+		return []string{}, err
+	}
+
+	members := make([]string, 0, len(e.set))
+	for member := range e.set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members, nil
+}
+
+func (m *Memory) SIsMember(_ context.Context, key string, member interface{}) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.read(key, kindSet)
+	if err != nil || e == nil {
+		return false, err
+	}
+	str, err := memoryValue(member)
+	if err != nil {
+		return false, err
+	}
+	_, ok := e.set[str]
+	return ok, nil
+}
+
+func (m *Memory) SCard(_ context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.scard(key)
+}
+
+func (m *Memory) scard(key string) (int64, error) {
+	e, err := m.read(key, kindSet)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	return int64(len(e.set)), nil
+}
+
+func (m *Memory) zadd(key string, members ...Z) (int64, error) {
+	converted := make(map[string]float64, len(members))
+	for _, member := range members {
+		str, err := memoryValue(member.Member)
+		if err != nil {
+			return 0, err
+		}
+		converted[str] = member.Score
+	}
+
+	e, err := m.write(key, kindZSet)
+	if err != nil {
+		return 0, err
+	}
+
+	var added int64
+	for member, score := range converted {
+		if _, ok := e.zset[member]; !ok {
+			added++
+		}
+		e.zset[member] = score
+	}
+	m.prune(key, e)
+	return added, nil
+}
+
+func (m *Memory) zrem(key string, members ...interface{}) (int64, error) {
+	e, err := m.read(key, kindZSet)
+	if err != nil || e == nil {
+		return 0, err
+	}
+
+	var removed int64
+	for _, member := range members {
+		str, err := memoryValue(member)
+		if err != nil {
+			return removed, err
+		}
+		if _, ok := e.zset[str]; ok {
+			delete(e.zset, str)
+			removed++
+		}
+	}
+	if removed > 0 {
+		m.touch(key)
+		m.prune(key, e)
+	}
+	return removed, nil
+}
+
+// sorted returns the members ordered by score, then member, as redis does.
+func (e *memoryEntry) sorted() []Z {
+	result := make([]Z, 0, len(e.zset))
+	for member, score := range e.zset {
+		result = append(result, Z{Score: score, Member: member})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score < result[j].Score
+		}
+		return result[i].Member.(string) < result[j].Member.(string)
+	})
+	return result
+}
+
+func (m *Memory) ZAdd(_ context.Context, key string, members ...Z) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.zadd(key, members...)
+}
+
+func (m *Memory) ZRem(_ context.Context, key string, members ...interface{}) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.zrem(key, members...)
+}
+
+func (m *Memory) ZScore(_ context.Context, key, member string) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.read(key, kindZSet)
+	if err != nil {
+		return 0, err
+	}
+	if e == nil {
+		return 0, NilType
+	}
+	score, ok := e.zset[member]
+	if !ok {
+		return 0, NilType
+	}
+	return score, nil
+}
+
+func (m *Memory) ZIncrBy(_ context.Context, key string, increment float64, member string) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.write(key, kindZSet)
+	if err != nil {
+		return 0, err
+	}
+	e.zset[member] += increment
+	return e.zset[member], nil
+}
+
+func (m *Memory) ZCard(_ context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.read(key, kindZSet)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	return int64(len(e.zset)), nil
+}
+
+func (m *Memory) ZRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	members, err := m.ZRangeWithScores(ctx, key, start, stop)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(members))
+	for _, member := range members {
+		result = append(result, member.Member.(string))
+	}
+	return result, nil
+}
+
+func (m *Memory) ZRangeWithScores(_ context.Context, key string, start, stop int64) ([]Z, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.read(key, kindZSet)
+	if err != nil || e == nil {
+		return []Z{}, err
+	}
+	sorted := e.sorted()
+	from, to := listRange(len(sorted), start, stop)
+	return sorted[from:to], nil
+}
+
+func (m *Memory) ZRangeByScore(_ context.Context, key string, min, max float64) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.read(key, kindZSet)
+	if err != nil || e == nil {
+		return []string{}, err
+	}
+
+	result := []string{}
+	for _, member := range e.sorted() {
+		if member.Score >= min && member.Score <= max {
+			result = append(result, member.Member.(string))
+		}
+	}
+	return result, nil
+}
+
+func (m *Memory) ZRemRangeByScore(_ context.Context, key string, min, max float64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.read(key, kindZSet)
+	if err != nil || e == nil {
+		return 0, err
+	}
+
+	var removed int64
+	for member, score := range e.zset {
+		if score >= min && score <= max {
+			delete(e.zset, member)
+			removed++
+		}
+	}
+	if removed > 0 {
+		m.touch(key)
+		m.prune(key, e)
+	}
+	return removed, nil
+}
@@ -0,0 +1,149 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	_redis "github.com/redis/go-redis/v9"
+)
+
+type pipeline struct {
+	ctx  context.Context
+	pipe _redis.Pipeliner
+}
+
+func (p *pipeline) Get(key string) *StringCmd {
+	return p.pipe.Get(p.ctx, key)
+}
+
+func (p *pipeline) Set(key string, value interface{}, expiration time.Duration) *StatusCmd {
+	return p.pipe.Set(p.ctx, key, value, expiration)
+}
+
+func (p *pipeline) SetNX(key string, value interface{}, expiration time.Duration) *BoolCmd {
+	return p.pipe.SetNX(p.ctx, key, value, expiration)
+}
+
+func (p *pipeline) Del(keys ...string) *IntCmd {
+	return p.pipe.Del(p.ctx, keys...)
+}
+
+func (p *pipeline) Exists(keys ...string) *IntCmd {
+	return p.pipe.Exists(p.ctx, keys...)
+}
+
+func (p *pipeline) Expire(key string, expiration time.Duration) *BoolCmd {
+	return p.pipe.Expire(p.ctx, key, expiration)
+}
+
+func (p *pipeline) Incr(key string) *IntCmd {
+	return p.pipe.Incr(p.ctx, key)
+}
+
+func (p *pipeline) IncrBy(key string, value int64) *IntCmd {
+	return p.pipe.IncrBy(p.ctx, key, value)
+}
+
+func (p *pipeline) HSet(key string, values map[string]interface{}) *IntCmd {
+	return p.pipe.HSet(p.ctx, key, values)
+}
+
+func (p *pipeline) HGet(key, field string) *StringCmd {
+	return p.pipe.HGet(p.ctx, key, field)
+}
+
+func (p *pipeline) HDel(key string, fields ...string) *IntCmd {
+	return p.pipe.HDel(p.ctx, key, fields...)
+}
+
+func (p *pipeline) HIncrBy(key, field string, incr int64) *IntCmd {
+	return p.pipe.HIncrBy(p.ctx, key, field, incr)
+}
+
+func (p *pipeline) LPush(key string, values ...interface{}) *IntCmd {
+	return p.pipe.LPush(p.ctx, key, values...)
+}
+
+func (p *pipeline) RPush(key string, values ...interface{}) *IntCmd {
+	return p.pipe.RPush(p.ctx, key, values...)
+}
+
+func (p *pipeline) SAdd(key string, members ...interface{}) *IntCmd {
+	return p.pipe.SAdd(p.ctx, key, members...)
+}
+
+func (p *pipeline) SRem(key string, members ...interface{}) *IntCmd {
+	return p.pipe.SRem(p.ctx, key, members...)
+}
+
+func (p *pipeline) SCard(key string) *IntCmd {
+	return p.pipe.SCard(p.ctx, key)
+}
+
+func (p *pipeline) ZAdd(key string, members ...Z) *IntCmd {
+	return p.pipe.ZAdd(p.ctx, key, members...)
+}
+
+func (p *pipeline) ZRem(key string, members ...interface{}) *IntCmd {
+	return p.pipe.ZRem(p.ctx, key, members...)
+}
+
+// pipelineErr drops NilType, which only means a queued read found no key; the command itself
+// still reports it.
+func pipelineErr(err error) error {
+	if err == nil || errors.Is(err, NilType) {
+		return nil
+	}
+	if errors.Is(err, ErrTxFailed) {
+		return ErrTxFailed
+	}
+	return fmt.Errorf("failed to execute pipeline: %w", err)
+}
+
+func (r *Client) Pipelined(ctx context.Context, fn func(pipe Pipeliner) error) error {
+	_, err := r.client.Pipelined(ctx, func(pipe _redis.Pipeliner) error {
+		return fn(&pipeline{ctx: ctx, pipe: pipe})
+	})
+	return pipelineErr(err)
+}
+
+func (r *Client) TxPipelined(ctx context.Context, fn func(pipe Pipeliner) error) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe _redis.Pipeliner) error {
+		return fn(&pipeline{ctx: ctx, pipe: pipe})
+	})
+	return pipelineErr(err)
+}
+
+type clientTx struct {
+	tx *_redis.Tx
+}
+
+func (t *clientTx) Get(ctx context.Context, key string) (string, error) {
+	result, err := t.tx.Get(ctx, key).Result()
+	return result, wrap(err, "get key", key)
+}
+
+func (t *clientTx) HGet(ctx context.Context, key, field string) (string, error) {
+	result, err := t.tx.HGet(ctx, key, field).Result()
+	return result, wrap(err, "get hash field", key+"."+field)
+}
+
+func (t *clientTx) Exists(ctx context.Context, keys ...string) (int64, error) {
+	result, err := t.tx.Exists(ctx, keys...).Result()
+	return result, wrap(err, "check key", fmt.Sprint(keys))
+}
+
+func (t *clientTx) TxPipelined(ctx context.Context, fn func(pipe Pipeliner) error) error {
+	_, err := t.tx.TxPipelined(ctx, func(pipe _redis.Pipeliner) error {
+		return fn(&pipeline{ctx: ctx, pipe: pipe})
+	})
+	return pipelineErr(err)
+}
+
+func (r *Client) Watch(ctx context.Context, fn func(tx Tx) error, keys ...string) error {
+	return r.client.Watch(ctx, func(tx *_redis.Tx) error {
+		return fn(&clientTx{tx: tx})
+	}, keys...)
+}
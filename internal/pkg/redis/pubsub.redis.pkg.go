@@ -0,0 +1,119 @@
+package redis
+
+import (
+	"boilerplate-go/internal/pkg/helper"
+	"boilerplate-go/internal/pkg/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Message is a pub/sub message or a stream entry. ID is only set for stream entries.
+type Message struct {
+	Channel   string
+	ID        string
+	Payload   []byte
+	RequestID string
+}
+
+// MessageHandler receives a context carrying the publisher's request id and a logger enriched with it.
+type MessageHandler func(ctx context.Context, msg *Message) error
+
+// JSONHandler decodes the payload into T before calling fn.
+func JSONHandler[T any](fn func(ctx context.Context, payload T, msg *Message) error) MessageHandler {
+	return func(ctx context.Context, msg *Message) error {
+		var payload T
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return fmt.Errorf("failed to decode message on %s: %w", msg.Channel, err)
+		}
+		return fn(ctx, payload, msg)
+	}
+}
+
+// envelope carries the request id next to the payload, since pub/sub has no headers.
+type envelope struct {
+	RequestID string          `json:"requestId,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// PublishJSON publishes payload encoded as JSON together with the request id of ctx.
+func PublishJSON(ctx context.Context, r IRedis, channel string, payload interface{}) (int64, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode message for %s: %w", channel, err)
+	}
+
+	message, err := json.Marshal(envelope{RequestID: helper.RequestIDFromContext(ctx), Payload: data})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode message for %s: %w", channel, err)
+	}
+	return r.Publish(ctx, channel, message)
+}
+
+// messageContext restores the publisher's request id into ctx, like rabbitmq.ContextFromDelivery.
+func messageContext(ctx context.Context, msg *Message) context.Context {
+	if helper.IsValidRequestID(msg.RequestID) {
+		ctx = helper.ContextWithRequestID(ctx, msg.RequestID)
+	}
+	if msg.ID != "" {
+		ctx = logger.With(ctx, "messageId", msg.ID)
+	}
+	return ctx
+}
+
+// handle runs handler with the publisher's request id restored into ctx and turns a panic into
+// an error so one bad message does not stop the consumer.
+func handle(ctx context.Context, handler MessageHandler, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return handler(messageContext(ctx, msg), msg)
+}
+
+type Listener struct {
+	pubsub  PubSub
+	handler MessageHandler
+	wg      sync.WaitGroup
+}
+
+// Listen subscribes to channels and calls handler for every message published with PublishJSON,
+// one at a time, until Close is called. Messages published while no instance listens are lost,
+// use a stream when delivery matters.
+func Listen(ctx context.Context, r IRedis, handler MessageHandler, channels ...string) (*Listener, error) {
+	pubsub, err := r.Subscribe(ctx, channels...)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Listener{pubsub: pubsub, handler: handler}
+	l.wg.Add(1)
+	go l.run(context.WithoutCancel(ctx))
+	return l, nil
+}
+
+func (l *Listener) run(ctx context.Context) {
+	defer l.wg.Done()
+
+	for received := range l.pubsub.Channel() {
+		var env envelope
+		if err := json.Unmarshal([]byte(received.Payload), &env); err != nil {
+			logger.FromContext(ctx).Warn("invalid pub/sub message", "channel", received.Channel, "error", err)
+			continue
+		}
+
+		msg := &Message{Channel: received.Channel, Payload: env.Payload, RequestID: env.RequestID}
+		if err := handle(ctx, l.handler, msg); err != nil {
+			logger.FromContext(messageContext(ctx, msg)).Error("pub/sub handler failed", "channel", msg.Channel, "error", err)
+		}
+	}
+}
+
+// Close unsubscribes and waits for the message being handled.
+func (l *Listener) Close() error {
+	err := l.pubsub.Close()
+	l.wg.Wait()
+	return err
+}
@@ -2,12 +2,15 @@ package redis
 
 import (
 	"boilerplate-go/internal/pkg/logger"
+	"boilerplate-go/internal/pkg/metrics"
 	"context"
-	"encoding/json"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	_redis "github.com/redis/go-redis/v9"
 )
 
@@ -27,68 +30,100 @@ func Setup(ctx context.Context, config *Config) (IRedis, error) {
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
-	// Start the reconnect handler
-	go r.reconnectHandler()
+	if config.Metrics {
+		name := strings.Join(config.addrs(), ",")
+		if err := metrics.RegisterRedisPool(name, func() *_redis.PoolStats { return r.client.PoolStats() }); err != nil {
+			cancel()
+			_ = r.client.Close()
+			return nil, fmt.Errorf("failed to register redis metrics: %w", err)
+		}
+	}
+
+	if config.HealthCheckInterval > 0 {
+		go r.healthCheck()
+	}
 
 	return r, nil
 }
 
-func (r *Client) connect() error {
-	r.client = _redis.NewClient(&_redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", r.config.Host, r.config.Port),
-		Password: r.config.Password,
-		PoolSize: r.config.PoolSize,
-	})
-
-	if err := r.client.Ping(r.ctx).Err(); err != nil {
-		return fmt.Errorf("failed to connect to redis: %w", err)
+// addrs returns Addrs, or Host:Port when Addrs is empty.
+func (c *Config) addrs() []string {
+	if len(c.Addrs) > 0 {
+		return c.Addrs
 	}
+	return []string{fmt.Sprintf("%s:%d", c.Host, c.Port)}
+}
 
-	return nil
+func (c *Config) universalOptions() *_redis.UniversalOptions {
+	opts := &_redis.UniversalOptions{
+		Addrs:            c.addrs(),
+		MasterName:       c.MasterName,
+		Username:         c.Username,
+		Password:         c.Password,
+		SentinelUsername: c.SentinelUsername,
+		SentinelPassword: c.SentinelPassword,
+		DB:               c.DB,
+		PoolSize:         c.PoolSize,
+		MaxRetries:       c.MaxRetries,
+		DialTimeout:      c.DialTimeout,
+		ReadTimeout:      c.ReadTimeout,
+		WriteTimeout:     c.WriteTimeout,
+		TLSConfig:        c.TLSConfig,
+	}
+	if opts.TLSConfig == nil && c.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return opts
 }
 
-func (r *Client) reconnect() error {
+// connect builds a sentinel client when MasterName is set, a cluster client when Cluster is set
+// or several addresses are given, and a single node client otherwise.
+func (r *Client) connect() error {
+	opts := r.config.universalOptions()
+	if r.config.Cluster && opts.MasterName == "" {
+		r.client = _redis.NewClusterClient(opts.Cluster())
+	} else {
+		r.client = _redis.NewUniversalClient(opts)
+	}
+
+	if r.config.Tracing {
+		if err := redisotel.InstrumentTracing(r.client); err != nil {
+			_ = r.client.Close()
+			return fmt.Errorf("failed to enable redis tracing: %w", err)
+		}
+	}
+
 	if err := r.client.Ping(r.ctx).Err(); err != nil {
-		return r.connect()
+		_ = r.client.Close()
+		return fmt.Errorf("failed to ping redis: %w", err)
 	}
+
 	return nil
 }
 
-func (r *Client) reconnectHandler() {
-	ticker := time.NewTicker(1 * time.Second)
+// healthCheck logs when redis becomes unreachable and when it recovers. Reconnecting is left to
+// go-redis, which replaces broken pool connections and retries commands on its own.
+func (r *Client) healthCheck() {
+	ticker := time.NewTicker(r.config.HealthCheckInterval)
 	defer ticker.Stop()
 
+	healthy := true
 	for {
 		select {
 		case <-r.ctx.Done():
-			logger.Info.Println("Reconnect handler shutting down...")
 			return
 		case <-ticker.C:
-			if err := r.client.Ping(r.ctx).Err(); err != nil {
-				logger.Warning.Printf("IRedis connection lost: %v. Attempting to reconnect...", err)
-
-				for attempt := 1; attempt <= 10; attempt++ {
-					select {
-					case <-r.ctx.Done():
-						logger.Warning.Println("Reconnect attempts stopped due to shutdown.")
-						return
-					default:
-						logger.Warning.Printf("Reconnect attempt #%d...", attempt)
-						time.Sleep(time.Duration(attempt) * time.Second) // Exponential backoff
-
-						// Reinitialize the IRedis general
-						if err = r.reconnect(); err == nil {
-							logger.Info.Println("Reconnected to IRedis.")
-							break
-						}
-						logger.Warning.Printf("Reconnect attempt failed: %v", err)
-					}
-				}
-
-				// If reconnection fails after max attempts, cancel the context
-				logger.Warning.Println("All reconnection attempts failed. Canceling context...")
-				r.cancel()
-				return
+			ctx, cancel := context.WithTimeout(r.ctx, r.config.HealthCheckInterval)
+			err := r.client.Ping(ctx).Err()
+			cancel()
+
+			switch {
+			case err != nil && healthy && r.ctx.Err() == nil:
+				logger.Warning.Printf("Redis connection lost: %v", err)
+				healthy = false
+			case err == nil && !healthy:
+				logger.Info.Println("Redis connection restored.")
+				healthy = true
 			}
 		}
 	}
@@ -100,49 +135,99 @@ func (r *Client) Close() error {
 	return r.client.Close()
 }
 
-// Set stores a key-value pair with an expiration time.
-func (r *Client) Set(key string, value any, expiration time.Duration) error {
-	data, err := json.Marshal(value)
-	if err != nil {
+func (r *Client) Ping(ctx context.Context) error {
+	return wrap(r.client.Ping(ctx).Err(), "ping", strings.Join(r.config.addrs(), ","))
+}
+
+// wrap adds the failed action to err. NilType is returned as is so it can be compared directly.
+func wrap(err error, action, key string) error {
+	if err == nil || errors.Is(err, NilType) {
 		return err
 	}
-	err = r.client.Set(r.ctx, key, data, expiration).Err()
-	if err != nil {
-		if err = r.reconnect(); err != nil {
-			return fmt.Errorf("failed to set key %s: %w", key, err)
-		}
-	}
-	return err
+	return fmt.Errorf("failed to %s %s: %w", action, key, err)
 }
 
 // Get retrieves the value of a key.
-func (r *Client) Get(key string) (string, error) {
-	result, err := r.client.Get(r.ctx, key).Result()
-	if err != nil {
-		if errors.Is(err, NilType) {
-			return "", nil // Key does not exist
-		}
-		if err = r.reconnect(); err != nil {
-			return "", fmt.Errorf("failed to get key %s: %w", key, err)
-		}
-	}
-	return result, nil
+func (r *Client) Get(ctx context.Context, key string) (string, error) {
+	result, err := r.client.Get(ctx, key).Result()
+	return result, wrap(err, "get key", key)
 }
 
-// Del deletes a key from IRedis.
-func (r *Client) Del(key string) error {
-	err := r.client.Del(r.ctx, key).Err()
-	if err != nil {
-		return fmt.Errorf("failed to delete key %s: %w", key, err)
-	}
-	return nil
+// Set stores a key-value pair with an expiration time.
+func (r *Client) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return wrap(r.client.Set(ctx, key, value, expiration).Err(), "set key", key)
+}
+
+// SetNX stores a key-value pair only if the key does not exist yet and reports whether it was set.
+func (r *Client) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	ok, err := r.client.SetNX(ctx, key, value, expiration).Result()
+	return ok, wrap(err, "set key", key)
+}
+
+// MGet returns the values of keys in order, with nil for missing keys.
+func (r *Client) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	result, err := r.client.MGet(ctx, keys...).Result()
+	return result, wrap(err, "get keys", strings.Join(keys, ","))
+}
+
+func (r *Client) MSet(ctx context.Context, values map[string]interface{}) error {
+	return wrap(r.client.MSet(ctx, values).Err(), "set keys", fmt.Sprint(len(values)))
+}
+
+func (r *Client) Incr(ctx context.Context, key string) (int64, error) {
+	return r.IncrBy(ctx, key, 1)
+}
+
+func (r *Client) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
+	result, err := r.client.IncrBy(ctx, key, value).Result()
+	return result, wrap(err, "increment key", key)
+}
+
+func (r *Client) Decr(ctx context.Context, key string) (int64, error) {
+	return r.DecrBy(ctx, key, 1)
 }
 
-// Expire sets a timeout on a key.
-func (r *Client) Expire(key string, expiration time.Duration) error {
-	err := r.client.Expire(r.ctx, key, expiration).Err()
-	if err != nil {
-		return fmt.Errorf("failed to set expiration on key %s: %w", key, err)
+func (r *Client) DecrBy(ctx context.Context, key string, value int64) (int64, error) {
+	result, err := r.client.DecrBy(ctx, key, value).Result()
+	return result, wrap(err, "decrement key", key)
+}
+
+// Del deletes keys and returns how many existed.
+func (r *Client) Del(ctx context.Context, keys ...string) (int64, error) {
+	result, err := r.client.Del(ctx, keys...).Result()
+	return result, wrap(err, "delete key", strings.Join(keys, ","))
+}
+
+// Exists returns how many of keys exist.
+func (r *Client) Exists(ctx context.Context, keys ...string) (int64, error) {
+	result, err := r.client.Exists(ctx, keys...).Result()
+	return result, wrap(err, "check key", strings.Join(keys, ","))
+}
+
+// Expire sets a timeout on a key and reports whether the key exists.
+func (r *Client) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	result, err := r.client.Expire(ctx, key, expiration).Result()
+	return result, wrap(err, "set expiration on key", key)
+}
+
+// TTL returns the remaining time to live of a key, -1 when it has no expiration and -2 when it
+// does not exist.
+func (r *Client) TTL(ctx context.Context, key string) (time.Duration, error) {
+	result, err := r.client.TTL(ctx, key).Result()
+	return result, wrap(err, "get ttl of key", key)
+}
+
+// Scan iterates the keyspace. Start with cursor 0 and stop when the returned cursor is 0.
+func (r *Client) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	keys, next, err := r.client.Scan(ctx, cursor, match, count).Result()
+	return keys, next, wrap(err, "scan keys", match)
+}
+
+// Eval runs a lua script atomically, using EVALSHA when the script is already cached.
+func (r *Client) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	result, err := _redis.NewScript(script).Run(ctx, r.client, keys, args...).Result()
+	if err != nil && !errors.Is(err, NilType) {
+		return nil, fmt.Errorf("failed to eval script: %w", err)
 	}
-	return nil
+	return result, nil
 }
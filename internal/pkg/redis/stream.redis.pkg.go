@@ -0,0 +1,235 @@
+package redis
+
+import (
+	"boilerplate-go/internal/pkg/helper"
+	"boilerplate-go/internal/pkg/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	streamFieldPayload   = "payload"
+	streamFieldRequestID = "requestId"
+
+	streamFieldSourceStream = "sourceStream"
+	streamFieldSourceID     = "sourceId"
+	streamFieldDeliveries   = "deliveries"
+)
+
+type StreamOptions struct {
+	Stream   string
+	Group    string
+	Consumer string
+	// StartID is where a newly created group starts reading, "0" for the whole stream or "$" for
+	// new entries only.
+	StartID string
+	Count   int64
+	Block   time.Duration
+	// ClaimMinIdle is how long an entry stays pending, because its handler failed or its consumer
+	// died, before it is claimed and handled again. 0 disables reclaiming.
+	ClaimMinIdle  time.Duration
+	ClaimInterval time.Duration
+	// MaxDeliveries is how often an entry is handed to the handler before it is moved to
+	// DeadLetterStream and acknowledged. 0 retries forever.
+	MaxDeliveries    int64
+	DeadLetterStream string
+}
+
+func DefaultStreamOptions(stream, group string) *StreamOptions {
+	hostname, _ := os.Hostname()
+	return &StreamOptions{
+		Stream:           stream,
+		Group:            group,
+		Consumer:         fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		StartID:          "0",
+		Count:            10,
+		Block:            5 * time.Second,
+		ClaimMinIdle:     time.Minute,
+		ClaimInterval:    30 * time.Second,
+		MaxDeliveries:    5,
+		DeadLetterStream: stream + ":dead",
+	}
+}
+
+// XAddJSON appends payload encoded as JSON together with the request id of ctx.
+func XAddJSON(ctx context.Context, r IRedis, stream string, maxLen int64, payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode entry for %s: %w", stream, err)
+	}
+
+	values := map[string]interface{}{streamFieldPayload: data}
+	if requestID := helper.RequestIDFromContext(ctx); requestID != "" {
+		values[streamFieldRequestID] = requestID
+	}
+	return r.XAdd(ctx, stream, maxLen, values)
+}
+
+// StreamConsumer reads a stream as one consumer of a group. Entries are acknowledged once the
+// handler succeeds; failed entries stay pending and are retried after ClaimMinIdle by whichever
+// consumer of the group claims them first. An entry claimed after MaxDeliveries deliveries is
+// copied to DeadLetterStream, with its source stream, id and delivery count, and acknowledged.
+type StreamConsumer struct {
+	redis     IRedis
+	handler   MessageHandler
+	opts      *StreamOptions
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	isRunning atomic.Bool
+}
+
+func NewStreamConsumer(r IRedis, handler MessageHandler, opts *StreamOptions) *StreamConsumer {
+	return &StreamConsumer{redis: r, handler: handler, opts: opts}
+}
+
+func (s *StreamConsumer) Start(ctx context.Context) error {
+	if s.isRunning.Swap(true) {
+		return fmt.Errorf("stream consumer is already running")
+	}
+
+	if err := s.redis.XGroupCreate(ctx, s.opts.Stream, s.opts.Group, s.opts.StartID); err != nil {
+		s.isRunning.Store(false)
+		return err
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.wg.Add(1)
+	go s.run(ctx)
+	return nil
+}
+
+// Stop waits for the current read and the entries being handled.
+func (s *StreamConsumer) Stop() error {
+	if !s.isRunning.Swap(false) {
+		return nil
+	}
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+func (s *StreamConsumer) IsHealthy() bool {
+	return s.isRunning.Load()
+}
+
+func (s *StreamConsumer) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	// Handlers and acknowledgements outlive Stop so an entry being handled is not left pending.
+	handleCtx := context.WithoutCancel(ctx)
+	var lastClaim time.Time
+
+	for ctx.Err() == nil {
+		if s.opts.ClaimMinIdle > 0 && time.Since(lastClaim) >= s.opts.ClaimInterval {
+			s.reclaim(ctx, handleCtx)
+			lastClaim = time.Now()
+		}
+
+		messages, err := s.redis.XReadGroup(ctx, s.opts.Group, s.opts.Consumer, s.opts.Stream, s.opts.Count, s.opts.Block)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Error.Printf("Stream %s read error: %v\n", s.opts.Stream, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		for _, message := range messages {
+			s.process(handleCtx, message)
+		}
+	}
+}
+
+func (s *StreamConsumer) reclaim(ctx, handleCtx context.Context) {
+	start := "0-0"
+	for ctx.Err() == nil {
+		messages, next, err := s.redis.XAutoClaim(ctx, s.opts.Stream, s.opts.Group, s.opts.Consumer, s.opts.ClaimMinIdle, start, s.opts.Count)
+		if err != nil {
+			logger.Error.Printf("Stream %s claim error: %v\n", s.opts.Stream, err)
+			return
+		}
+
+		for _, message := range messages {
+			if deliveries := s.deliveries(ctx, message.ID); s.opts.MaxDeliveries > 0 && deliveries > s.opts.MaxDeliveries {
+				s.deadLetter(handleCtx, message, deliveries)
+				continue
+			}
+			s.process(handleCtx, message)
+		}
+		if next == "0-0" || next == "" {
+			return
+		}
+		start = next
+	}
+}
+
+func (s *StreamConsumer) process(ctx context.Context, message XMessage) {
+	payload, ok := message.Values[streamFieldPayload].(string)
+	if !ok {
+		// The entry will never become valid, so it is acknowledged instead of being retried.
+		logger.Warning.Printf("Stream %s entry %s has no payload, dropping it\n", s.opts.Stream, message.ID)
+		s.ack(ctx, message.ID)
+		return
+	}
+
+	requestID, _ := message.Values[streamFieldRequestID].(string)
+	msg := &Message{Channel: s.opts.Stream, ID: message.ID, Payload: []byte(payload), RequestID: requestID}
+	if err := handle(ctx, s.handler, msg); err != nil {
+		logger.FromContext(messageContext(ctx, msg)).Error("stream handler failed", "stream", s.opts.Stream, "error", err)
+		return
+	}
+	s.ack(ctx, message.ID)
+}
+
+// deliveries returns how often a pending entry was delivered, or 0 when MaxDeliveries is off or
+// the count cannot be read. XAUTOCLAIM counts the claim itself, so the first retry of a failed
+// entry has 2 deliveries.
+func (s *StreamConsumer) deliveries(ctx context.Context, id string) int64 {
+	if s.opts.MaxDeliveries <= 0 {
+		return 0
+	}
+
+	pending, err := s.redis.XPendingExt(ctx, s.opts.Stream, s.opts.Group, id, id, 1)
+	if err != nil {
+		logger.Error.Printf("Stream %s failed to read delivery count of %s: %v\n", s.opts.Stream, id, err)
+		return 0
+	}
+	if len(pending) == 0 {
+		return 0
+	}
+	return pending[0].RetryCount
+}
+
+// deadLetter moves an entry to DeadLetterStream. It stays pending when the copy fails, so it is
+// moved again on the next claim.
+func (s *StreamConsumer) deadLetter(ctx context.Context, message XMessage, deliveries int64) {
+	values := make(map[string]interface{}, len(message.Values)+3)
+	for field, value := range message.Values {
+		values[field] = value
+	}
+	values[streamFieldSourceStream] = s.opts.Stream
+	values[streamFieldSourceID] = message.ID
+	values[streamFieldDeliveries] = deliveries
+
+	if _, err := s.redis.XAdd(ctx, s.opts.DeadLetterStream, 0, values); err != nil {
+		logger.Error.Printf("Stream %s failed to dead-letter %s: %v\n", s.opts.Stream, message.ID, err)
+		return
+	}
+	logger.Warning.Printf("Stream %s entry %s failed %d deliveries, moved to %s\n", s.opts.Stream, message.ID, deliveries-1, s.opts.DeadLetterStream)
+	s.ack(ctx, message.ID)
+}
+
+func (s *StreamConsumer) ack(ctx context.Context, id string) {
+	if _, err := s.redis.XAck(ctx, s.opts.Stream, s.opts.Group, id); err != nil {
+		logger.Error.Printf("Stream %s failed to acknowledge %s: %v\n", s.opts.Stream, id, err)
+	}
+}
@@ -2,33 +2,188 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
 	"time"
 
 	_redis "github.com/redis/go-redis/v9"
 )
 
 type Config struct {
-	Host     string
-	Port     int
-	Password string
-	PoolSize int
+	Host string
+	Port int
+	// Addrs overrides Host and Port. Several addresses select cluster mode, sentinel addresses
+	// are given together with MasterName.
+	Addrs      []string
+	MasterName string
+	Cluster    bool
+
+	Username         string
+	Password         string
+	SentinelUsername string
+	SentinelPassword string
+	DB               int
+
+	// TLS enables TLS 1.2+ with the system roots; TLSConfig takes precedence when set.
+	TLS       bool
+	TLSConfig *tls.Config
+
+	PoolSize     int
+	MaxRetries   int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// HealthCheckInterval enables a background ping that logs connection loss and recovery.
+	HealthCheckInterval time.Duration
+
+	Tracing bool
+	Metrics bool
 }
 
 type Client struct {
-	client *_redis.Client
+	client _redis.UniversalClient
 	config *Config
 	cancel context.CancelFunc
 	ctx    context.Context
 }
 
+// IRedis is the redis API used by the application. Missing keys are reported with NilType, see
+// IsNil. Values are written as is; use SetJSON and GetJSON for structured values.
 type IRedis interface {
 	Close() error
-	Set(key string, value interface{}, expiration time.Duration) error
-	Get(key string) (string, error)
-	Del(key string) error
-	Expire(key string, expiration time.Duration) error
+	Ping(ctx context.Context) error
+
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	MGet(ctx context.Context, keys ...string) ([]interface{}, error)
+	MSet(ctx context.Context, values map[string]interface{}) error
+	Incr(ctx context.Context, key string) (int64, error)
+	IncrBy(ctx context.Context, key string, value int64) (int64, error)
+	Decr(ctx context.Context, key string) (int64, error)
+	DecrBy(ctx context.Context, key string, value int64) (int64, error)
+
+	Del(ctx context.Context, keys ...string) (int64, error)
+	Exists(ctx context.Context, keys ...string) (int64, error)
+	Expire(ctx context.Context, key string, expiration time.Duration) (bool, error)
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error)
+
+	HGet(ctx context.Context, key, field string) (string, error)
+	HSet(ctx context.Context, key string, values map[string]interface{}) (int64, error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	HDel(ctx context.Context, key string, fields ...string) (int64, error)
+	HExists(ctx context.Context, key, field string) (bool, error)
+	HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error)
+
+	LPush(ctx context.Context, key string, values ...interface{}) (int64, error)
+	RPush(ctx context.Context, key string, values ...interface{}) (int64, error)
+	LPop(ctx context.Context, key string) (string, error)
+	RPop(ctx context.Context, key string) (string, error)
+	LRange(ctx context.Context, key string, start, stop int64) ([]string, error)
+	LLen(ctx context.Context, key string) (int64, error)
+	LTrim(ctx context.Context, key string, start, stop int64) error
+
+	SAdd(ctx context.Context, key string, members ...interface{}) (int64, error)
+	SRem(ctx context.Context, key string, members ...interface{}) (int64, error)
+	SMembers(ctx context.Context, key string) ([]string, error)
+	SIsMember(ctx context.Context, key string, member interface{}) (bool, error)
+	SCard(ctx context.Context, key string) (int64, error)
+
+	ZAdd(ctx context.Context, key string, members ...Z) (int64, error)
+	ZRem(ctx context.Context, key string, members ...interface{}) (int64, error)
+	ZScore(ctx context.Context, key, member string) (float64, error)
+	ZIncrBy(ctx context.Context, key string, increment float64, member string) (float64, error)
+	ZCard(ctx context.Context, key string) (int64, error)
+	ZRange(ctx context.Context, key string, start, stop int64) ([]string, error)
+	ZRangeWithScores(ctx context.Context, key string, start, stop int64) ([]Z, error)
+	ZRangeByScore(ctx context.Context, key string, min, max float64) ([]string, error)
+	ZRemRangeByScore(ctx context.Context, key string, min, max float64) (int64, error)
+
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+
+	Publish(ctx context.Context, channel string, message interface{}) (int64, error)
+	// Subscribe listens on channels until the returned PubSub is closed.
+	Subscribe(ctx context.Context, channels ...string) (PubSub, error)
+
+	// XAdd appends an entry to stream, trimming it to about maxLen entries when maxLen > 0.
+	XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error)
+	// XGroupCreate creates group and the stream if needed. An existing group is not an error.
+	XGroupCreate(ctx context.Context, stream, group, start string) error
+	// XReadGroup reads new entries for consumer, waiting up to block; a negative block does not
+	// wait. It returns no entries and no error when the wait times out.
+	XReadGroup(ctx context.Context, group, consumer, stream string, count int64, block time.Duration) ([]XMessage, error)
+	XAck(ctx context.Context, stream, group string, ids ...string) (int64, error)
+	// XAutoClaim transfers entries pending for longer than minIdle to consumer. Continue from the
+	// returned cursor until it is "0-0".
+	XAutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, start string, count int64) ([]XMessage, string, error)
+	// XPendingExt lists up to count pending entries of group with ids between start and end,
+	// including how often each was delivered.
+	XPendingExt(ctx context.Context, stream, group, start, end string, count int64) ([]XPendingExt, error)
+	XLen(ctx context.Context, stream string) (int64, error)
+
+	// Pipelined sends every command queued by fn in one round trip.
+	Pipelined(ctx context.Context, fn func(pipe Pipeliner) error) error
+	// TxPipelined is Pipelined wrapped in MULTI/EXEC.
+	TxPipelined(ctx context.Context, fn func(pipe Pipeliner) error) error
+	// Watch runs fn with keys watched; a TxPipelined call inside fn fails with ErrTxFailed when
+	// one of them changed in the meantime.
+	Watch(ctx context.Context, fn func(tx Tx) error, keys ...string) error
+}
+
+// Pipeliner queues commands. Their results are available on the returned commands once the
+// pipeline has been executed.
+type Pipeliner interface {
+	Get(key string) *StringCmd
+	Set(key string, value interface{}, expiration time.Duration) *StatusCmd
+	SetNX(key string, value interface{}, expiration time.Duration) *BoolCmd
+	Del(keys ...string) *IntCmd
+	Exists(keys ...string) *IntCmd
+	Expire(key string, expiration time.Duration) *BoolCmd
+	Incr(key string) *IntCmd
+	IncrBy(key string, value int64) *IntCmd
+	HSet(key string, values map[string]interface{}) *IntCmd
+	HGet(key, field string) *StringCmd
+	HDel(key string, fields ...string) *IntCmd
+	HIncrBy(key, field string, incr int64) *IntCmd
+	LPush(key string, values ...interface{}) *IntCmd
+	RPush(key string, values ...interface{}) *IntCmd
+	SAdd(key string, members ...interface{}) *IntCmd
+	SRem(key string, members ...interface{}) *IntCmd
+	SCard(key string) *IntCmd
+	ZAdd(key string, members ...Z) *IntCmd
+	ZRem(key string, members ...interface{}) *IntCmd
+}
+
+// Tx is the read side of a watched transaction.
+type Tx interface {
+	Get(ctx context.Context, key string) (string, error)
+	HGet(ctx context.Context, key, field string) (string, error)
+	Exists(ctx context.Context, keys ...string) (int64, error)
+	TxPipelined(ctx context.Context, fn func(pipe Pipeliner) error) error
+}
+
+// PubSub delivers the messages of a subscription until it is closed.
+type PubSub interface {
+	Channel() <-chan *PubSubMessage
+	Close() error
 }
 
 type ClientType = _redis.Client
 
+type (
+	Z         = _redis.Z
+	StringCmd = _redis.StringCmd
+	StatusCmd = _redis.StatusCmd
+	BoolCmd   = _redis.BoolCmd
+	IntCmd    = _redis.IntCmd
+
+	PubSubMessage = _redis.Message
+	XMessage      = _redis.XMessage
+	XPendingExt   = _redis.XPendingExt
+)
+
 const NilType = _redis.Nil
+
+// ErrTxFailed is returned by TxPipelined inside Watch when a watched key was modified.
+var ErrTxFailed = _redis.TxFailedErr
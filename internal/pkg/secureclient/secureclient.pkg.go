@@ -0,0 +1,161 @@
+package secureclient
+
+import (
+	"boilerplate-go/internal/common/enum"
+	"boilerplate-go/internal/pkg/helper"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const nonceSize = 16
+
+func New(config *Config) IClient {
+	return &Client{config: config}
+}
+
+// Do encrypts the body, signs the request and sends it through helper.HTTPRequest.
+func (cl *Client) Do(ctx context.Context, req *Request) (*helper.HTTPAPIResponse, error) {
+	target, err := cl.buildURL(req.Path, req.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	var body interface{}
+	var rawBody []byte
+	if req.Method != enum.GET {
+		if req.Body != nil {
+			envelope, err := cl.EncryptBody(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			body = envelope
+		}
+		// helper.HTTPRequest marshals the body with json.Marshal as well, so the signed
+		// bytes are exactly the bytes sent on the wire.
+		rawBody, err = json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	headers, err := cl.SignHeaders(req.Method, target.Path, target.Query(), rawBody)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range req.Headers {
+		headers[key] = append(headers[key], values...)
+	}
+	headers.Set("Content-Type", enum.ApplicationJSON.ToString())
+
+	return helper.HTTPRequest(&helper.HTTPRequestPayload{
+		Method: req.Method,
+		URL:    target.String(),
+		Body:   body,
+	}, &helper.HTTPRequestConfig{
+		Ctx:       ctx,
+		Headers:   headers,
+		HTTPAgent: cl.config.HTTPAgent,
+	})
+}
+
+// EncryptBody produces the {"data": "..."} envelope EncryptMiddleware expects.
+func (cl *Client) EncryptBody(body interface{}) (map[string]string, error) {
+	plaintext, err := helper.JSONToString(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var data string
+	if cl.config.Legacy {
+		data, err = helper.EncryptAESCBC(plaintext)
+	} else {
+		data, err = helper.EncryptPayload(plaintext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt body: %w", err)
+	}
+
+	return map[string]string{"data": data}, nil
+}
+
+// DecryptBody decrypts a payload in either the authenticated or the legacy format.
+func (cl *Client) DecryptBody(data string) (map[string]interface{}, error) {
+	plaintext, err := helper.DecryptPayload(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt body: %w", err)
+	}
+	return helper.StringToJSON(plaintext)
+}
+
+// SignHeaders returns the tenant, version, time, nonce and signature headers for a request.
+func (cl *Client) SignHeaders(method enum.HTTPMethodEnum, path string, query url.Values, body []byte) (http.Header, error) {
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now().Unix()
+	signature, err := helper.SignRequest(method.ToString(), path, query, body, timestamp, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	for key, values := range cl.config.Headers {
+		headers[key] = append(headers[key], values...)
+	}
+	if cl.config.Tenant != "" {
+		headers.Set("x-tenant", cl.config.Tenant)
+	}
+	if cl.config.Version != "" {
+		headers.Set("version", cl.config.Version)
+	}
+	if cl.config.Token != "" {
+		headers.Set("Authorization", "Bearer "+cl.config.Token)
+	}
+	headers.Set(helper.HeaderTime, strconv.FormatInt(timestamp, 10))
+	headers.Set(helper.HeaderNonce, nonce)
+	headers.Set(helper.HeaderSignature, signature)
+
+	return headers, nil
+}
+
+// LegacyHeaders returns the x-time/x-encrypt pair, which servers only accept with HEADER_LEGACY=1.
+func LegacyHeaders(timestamp int64) (http.Header, error) {
+	encrypted, err := helper.HMACSHA256(helper.GetEnv("HEADER_CODE") + ":" + strconv.FormatInt(timestamp, 10))
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	headers.Set(helper.HeaderTime, strconv.FormatInt(timestamp, 10))
+	headers.Set("x-encrypt", encrypted)
+	return headers, nil
+}
+
+func (cl *Client) buildURL(path string, query url.Values) (*url.URL, error) {
+	target, err := url.Parse(strings.TrimSuffix(cl.config.BaseURL, "/") + path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if len(query) > 0 {
+		target.RawQuery = query.Encode()
+	}
+	return target, nil
+}
+
+func generateNonce() (string, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return hex.EncodeToString(nonce), nil
+}
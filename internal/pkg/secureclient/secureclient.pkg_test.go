@@ -0,0 +1,184 @@
+package secureclient
+
+import (
+	"boilerplate-go/internal/common/enum"
+	_type "boilerplate-go/internal/common/type"
+	"boilerplate-go/internal/pkg/helper"
+	"boilerplate-go/internal/pkg/middleware"
+	"boilerplate-go/internal/pkg/redis"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testTenant = "test"
+
+func setupEnv(t *testing.T) {
+	t.Setenv("PAYLOAD_KEYS", "k1:"+base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	t.Setenv("PAYLOAD_KEY_CURRENT", "k1")
+	t.Setenv("PAYLOAD_LEGACY", "0")
+	t.Setenv("ENCRYPT_KEY", "0123456789abcdef0123456789abcdef")
+	t.Setenv("HEADER_TIME", "60")
+	t.Setenv("HEADER_LEGACY", "0")
+	t.Setenv("APP_TENANT", testTenant)
+	t.Setenv("DEV", "")
+	t.Setenv("DEV_HOST", "")
+}
+
+// newEchoServer decrypts the request through EncryptMiddleware and answers with the body and
+// query encrypted in the same envelope.
+func newEchoServer(t *testing.T) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ResponseInit(), middleware.EncryptMiddleware(redis.NewMemory()))
+	r.POST("/echo", func(c *gin.Context) {
+		send := c.MustGet("send").(func(r *_type.Response))
+		body, _ := c.Get("body")
+		plaintext, err := helper.JSONToString(map[string]interface{}{
+			"body":  body,
+			"query": c.Query("q"),
+		})
+		if err != nil {
+			t.Errorf("marshal response: %v", err)
+		}
+		data, err := helper.EncryptPayload(plaintext)
+		if err != nil {
+			t.Errorf("encrypt response: %v", err)
+		}
+		send(&_type.Response{Data: map[string]string{"data": data}})
+	})
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(baseURL string) *Client {
+	return New(&Config{BaseURL: baseURL, Tenant: testTenant}).(*Client)
+}
+
+// signedRequest builds the request Do would send, so tests can replay or tamper with it.
+func signedRequest(t *testing.T, cl *Client, baseURL string, body interface{}) (*http.Request, []byte) {
+	envelope, err := cl.EncryptBody(body)
+	if err != nil {
+		t.Fatalf("EncryptBody: %v", err)
+	}
+	raw, _ := json.Marshal(envelope)
+
+	headers, err := cl.SignHeaders(enum.POST, "/echo", url.Values{}, raw)
+	if err != nil {
+		t.Fatalf("SignHeaders: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, baseURL+"/echo", bytes.NewReader(raw))
+	req.Header = headers
+	req.Header.Set("Content-Type", "application/json")
+	return req, raw
+}
+
+func TestDoRoundTrip(t *testing.T) {
+	setupEnv(t)
+	srv := newEchoServer(t)
+	cl := newTestClient(srv.URL)
+
+	resp, err := cl.Do(context.Background(), &Request{
+		Method: enum.POST,
+		Path:   "/echo",
+		Query:  url.Values{"q": {"search term"}},
+		Body:   map[string]interface{}{"name": "alice"},
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", resp.StatusCode, resp.Data)
+	}
+
+	envelope, _ := resp.Data.(map[string]interface{})["data"].(map[string]interface{})
+	data, _ := envelope["data"].(string)
+	decrypted, err := cl.DecryptBody(data)
+	if err != nil {
+		t.Fatalf("DecryptBody: %v", err)
+	}
+
+	body, _ := decrypted["body"].(map[string]interface{})
+	if body["name"] != "alice" {
+		t.Errorf("echoed body = %v, want name alice", decrypted["body"])
+	}
+	if decrypted["query"] != "search term" {
+		t.Errorf("echoed query = %v, want %q", decrypted["query"], "search term")
+	}
+}
+
+func TestReplayedNonceIsRejected(t *testing.T) {
+	setupEnv(t)
+	srv := newEchoServer(t)
+	cl := newTestClient(srv.URL)
+
+	req, raw := signedRequest(t, cl, srv.URL, map[string]interface{}{"name": "alice"})
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("first status = %d, want 200", resp.StatusCode)
+	}
+
+	replay, _ := http.NewRequest(http.MethodPost, srv.URL+"/echo", bytes.NewReader(raw))
+	replay.Header = req.Header.Clone()
+	resp, err = http.DefaultClient.Do(replay)
+	if err != nil {
+		t.Fatalf("replayed request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("replayed status = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestTamperedRequestIsRejected(t *testing.T) {
+	setupEnv(t)
+	srv := newEchoServer(t)
+	cl := newTestClient(srv.URL)
+
+	tests := map[string]func(req *http.Request){
+		"body": func(req *http.Request) {
+			envelope, _ := cl.EncryptBody(map[string]interface{}{"name": "mallory"})
+			raw, _ := json.Marshal(envelope)
+			req.Body, req.ContentLength = io.NopCloser(bytes.NewReader(raw)), int64(len(raw))
+		},
+		"query": func(req *http.Request) {
+			req.URL.RawQuery = "q=injected"
+		},
+		"signature": func(req *http.Request) {
+			signature := []byte(req.Header.Get(helper.HeaderSignature))
+			signature[0] ^= 1
+			req.Header.Set(helper.HeaderSignature, string(signature))
+		},
+	}
+
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, _ := signedRequest(t, cl, srv.URL, map[string]interface{}{"name": "alice"})
+			tamper(req)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401", resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package secureclient
+
+import (
+	"boilerplate-go/internal/common/enum"
+	"boilerplate-go/internal/pkg/helper"
+	"context"
+	"net/http"
+	"net/url"
+)
+
+type Config struct {
+	BaseURL   string
+	Tenant    string
+	Version   string
+	Token     string
+	Legacy    bool
+	Headers   http.Header
+	HTTPAgent *http.Transport
+}
+
+type Request struct {
+	Method  enum.HTTPMethodEnum
+	Path    string
+	Query   url.Values
+	Body    interface{}
+	Headers http.Header
+}
+
+type Client struct {
+	config *Config
+}
+
+type IClient interface {
+	Do(ctx context.Context, req *Request) (*helper.HTTPAPIResponse, error)
+	EncryptBody(body interface{}) (map[string]string, error)
+	DecryptBody(data string) (map[string]interface{}, error)
+	SignHeaders(method enum.HTTPMethodEnum, path string, query url.Values, body []byte) (http.Header, error)
+}
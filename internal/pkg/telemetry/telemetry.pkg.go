@@ -0,0 +1,132 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+
+	instrumentationName = "boilerplate-go"
+)
+
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	Exporter       string
+	OTLPEndpoint   string
+	Insecure       bool
+	SampleRatio    float64
+}
+
+// DefaultConfig reads OTEL_SERVICE_NAME, OTEL_SERVICE_VERSION, APP_ENV, OTEL_EXPORTER (otlp, stdout, none),
+// OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_INSECURE and OTEL_TRACES_SAMPLER_ARG.
+func DefaultConfig() *Config {
+	cfg := &Config{
+		ServiceName:    os.Getenv("OTEL_SERVICE_NAME"),
+		ServiceVersion: os.Getenv("OTEL_SERVICE_VERSION"),
+		Environment:    os.Getenv("APP_ENV"),
+		Exporter:       os.Getenv("OTEL_EXPORTER"),
+		OTLPEndpoint:   os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		Insecure:       os.Getenv("OTEL_EXPORTER_OTLP_INSECURE") == "1",
+		SampleRatio:    1,
+	}
+
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = instrumentationName
+	}
+	if cfg.Exporter == "" {
+		cfg.Exporter = ExporterNone
+	}
+	if value, err := strconv.ParseFloat(os.Getenv("OTEL_TRACES_SAMPLER_ARG"), 64); err == nil {
+		cfg.SampleRatio = value
+	}
+
+	return cfg
+}
+
+// Setup installs the global tracer provider and W3C trace context propagator. The returned
+// function flushes pending spans and must be called on shutdown.
+func Setup(ctx context.Context, cfg *Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if cfg.Exporter == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(cfg.ServiceVersion),
+		semconv.DeploymentEnvironment(cfg.Environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create telemetry resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, cfg *Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		// OTEL_EXPORTER_OTLP_ENDPOINT is a URL such as http://collector:4318, so it goes through
+		// WithEndpointURL; WithEndpoint only takes host:port.
+		var opts []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+		}
+		return exporter, nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		return exporter, nil
+	default:
+		return nil, errors.New("unsupported telemetry exporter: " + cfg.Exporter)
+	}
+}
+
+// Tracer returns the tracer used by the instrumentation in this repository.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
@@ -0,0 +1,68 @@
+package validation
+
+import (
+	"boilerplate-go/internal/pkg/apperror"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// maxFormMemory matches the multipart memory limit gin's form binding uses.
+const maxFormMemory = 32 << 20
+
+// BindAndValidate binds the request into obj from every source in order (JSON when none is
+// given), then validates the result once with messages in the Accept-Language of the request.
+// Malformed input is returned as a bad request apperror, failed rules as ValidationErrors.
+func BindAndValidate(c *gin.Context, obj any, sources ...Source) error {
+	if len(sources) == 0 {
+		sources = []Source{SourceJSON}
+	}
+
+	// Sources are decoded without gin's validator: rules are checked once every source is bound,
+	// so a field required by a later source does not fail an earlier bind.
+	for _, source := range sources {
+		if err := bindSource(c, obj, source); err != nil {
+			return apperror.BadRequest("Invalid request payload").Wrap(err)
+		}
+	}
+
+	return ValidateCtx(c.Request.Context(), obj, LanguageFromHeader(c.GetHeader("Accept-Language")))
+}
+
+// bindSource decodes one source the way the matching gin binding does, minus its validation.
+func bindSource(c *gin.Context, obj any, source Source) error {
+	switch source {
+	case SourceJSON:
+		if c.Request.Body == nil {
+			return errors.New("invalid request")
+		}
+		decoder := json.NewDecoder(c.Request.Body)
+		if binding.EnableDecoderUseNumber {
+			decoder.UseNumber()
+		}
+		if binding.EnableDecoderDisallowUnknownFields {
+			decoder.DisallowUnknownFields()
+		}
+		return decoder.Decode(obj)
+	case SourceQuery:
+		return binding.MapFormWithTag(obj, c.Request.URL.Query(), "form")
+	case SourceURI:
+		params := make(map[string][]string, len(c.Params))
+		for _, param := range c.Params {
+			params[param.Key] = []string{param.Value}
+		}
+		return binding.MapFormWithTag(obj, params, "uri")
+	case SourceForm:
+		if err := c.Request.ParseForm(); err != nil {
+			return err
+		}
+		if err := c.Request.ParseMultipartForm(maxFormMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return err
+		}
+		return binding.MapFormWithTag(obj, c.Request.Form, "form")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package validation
+
+import (
+	"boilerplate-go/internal/pkg/apperror"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type bindPayload struct {
+	Name string `json:"name" form:"name" validate:"required,counted"`
+	Page int    `json:"page" form:"page" validate:"required,min=1"`
+}
+
+func bindRequest(t *testing.T, target, body, lang string, obj any, sources ...Source) error {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if lang != "" {
+		c.Request.Header.Set("Accept-Language", lang)
+	}
+	return BindAndValidate(c, obj, sources...)
+}
+
+func TestBindAndValidateRunsRulesOnce(t *testing.T) {
+	countedCalls.Store(0)
+
+	var payload bindPayload
+	if err := bindRequest(t, "/items?page=2", `{"name":"alice"}`, "", &payload, SourceJSON, SourceQuery); err != nil {
+		t.Fatalf("BindAndValidate: %v", err)
+	}
+	if payload.Name != "alice" || payload.Page != 2 {
+		t.Errorf("payload = %+v, want fields from the body and the query", payload)
+	}
+	if got := countedCalls.Load(); got != 1 {
+		t.Errorf("rule ran %d times, want once", got)
+	}
+}
+
+func TestBindAndValidateLocalizesErrors(t *testing.T) {
+	var payload bindPayload
+	err := bindRequest(t, "/items", `{}`, "id-ID,id;q=0.9", &payload, SourceJSON, SourceQuery)
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("BindAndValidate = %v, want ValidationErrors", err)
+	}
+	if len(errs) != 2 || errs[0].Field != "name" || errs[0].Message != "wajib diisi" || errs[1].Field != "page" {
+		t.Errorf("errors = %+v, want name and page required in Indonesian", errs)
+	}
+}
+
+func TestBindAndValidateMalformedInput(t *testing.T) {
+	tests := map[string]struct {
+		target  string
+		body    string
+		sources []Source
+	}{
+		"json":  {"/items", `{"name":`, nil},
+		"query": {"/items?page=abc", `{}`, []Source{SourceQuery}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var payload bindPayload
+			err := bindRequest(t, tt.target, tt.body, "", &payload, tt.sources...)
+
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || appErr.Code != apperror.CodeBadRequest {
+				t.Fatalf("BindAndValidate = %v, want a bad request", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package validation
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	LanguageEnglish    = "en"
+	LanguageIndonesian = "id"
+)
+
+var DefaultLanguage = LanguageEnglish
+
+// messagesMu guards validationMessages, which RegisterMessage may change while requests are
+// translated.
+var messagesMu sync.RWMutex
+
+var validationMessages = map[string]map[string]string{
+	LanguageEnglish: {
+		"e164":            "must be a e164 formatted phone number",
+		"required":        "is required",
+		"url":             "must be a valid URL",
+		"datetime":        "must be a valid date-time format (2006-01-02T15:04:05Z07:00)",
+		"number":          "must be a number",
+		"oneof":           "must be one of the allowed values: %s",
+		"email":           "must be a valid email address",
+		"min":             "must be greater than or equal to %s",
+		"max":             "must be less than or equal to %s",
+		"len":             "must have the exact length of %s",
+		"alpha":           "must contain only alphabetic characters",
+		"alphanum":        "must contain only alphanumeric characters",
+		"eqfield":         "must be equal to the value of the %s field",
+		"nefield":         "must not be equal to the value of the %s field",
+		"gt":              "must be greater than %s",
+		"gte":             "must be greater than or equal to %s",
+		"lt":              "must be less than %s",
+		"lte":             "must be less than or equal to %s",
+		"excludes":        "must not contain the value %s",
+		"excludesall":     "must not contain any of the values: %s",
+		"enum":            "must be one of the allowed enum values: %s",
+		"stringToBool":    "must be a boolean value",
+		"phone_id":        "must be a valid Indonesian phone number",
+		"nik":             "must be a valid 16 digit NIK",
+		"strong_password": "must be at least %s characters long and contain upper and lower case letters, a number and a symbol",
+		"file_mime":       "must be a file of type: %s",
+		"file_size":       "must not be larger than %s",
+		"unique":          "must not contain duplicate values",
+		"db_unique":       "is already taken",
+		"after_field":     "must be after %s",
+		"before_field":    "must be before %s",
+		"slug":            "must contain only lowercase letters, numbers and single hyphens",
+		"username":        "must be 3 to 32 letters or numbers, optionally separated by single dots or underscores",
+		"default":         "is invalid",
+	},
+	LanguageIndonesian: {
+		"e164":            "harus berupa nomor telepon berformat e164",
+		"required":        "wajib diisi",
+		"url":             "harus berupa URL yang valid",
+		"datetime":        "harus berupa format tanggal-waktu yang valid (2006-01-02T15:04:05Z07:00)",
+		"number":          "harus berupa angka",
+		"oneof":           "harus salah satu dari nilai berikut: %s",
+		"email":           "harus berupa alamat email yang valid",
+		"min":             "harus lebih besar dari atau sama dengan %s",
+		"max":             "harus lebih kecil dari atau sama dengan %s",
+		"len":             "harus memiliki panjang tepat %s",
+		"alpha":           "hanya boleh berisi huruf",
+		"alphanum":        "hanya boleh berisi huruf dan angka",
+		"eqfield":         "harus sama dengan nilai field %s",
+		"nefield":         "tidak boleh sama dengan nilai field %s",
+		"gt":              "harus lebih besar dari %s",
+		"gte":             "harus lebih besar dari atau sama dengan %s",
+		"lt":              "harus lebih kecil dari %s",
+		"lte":             "harus lebih kecil dari atau sama dengan %s",
+		"excludes":        "tidak boleh mengandung nilai %s",
+		"excludesall":     "tidak boleh mengandung nilai berikut: %s",
+		"enum":            "harus salah satu dari nilai enum yang diizinkan: %s",
+		"stringToBool":    "harus berupa nilai boolean",
+		"phone_id":        "harus berupa nomor telepon Indonesia yang valid",
+		"nik":             "harus berupa NIK 16 digit yang valid",
+		"strong_password": "minimal %s karakter dan harus mengandung huruf besar, huruf kecil, angka dan simbol",
+		"file_mime":       "harus berupa file dengan tipe: %s",
+		"file_size":       "tidak boleh lebih besar dari %s",
+		"unique":          "tidak boleh berisi nilai duplikat",
+		"db_unique":       "sudah digunakan",
+		"after_field":     "harus setelah %s",
+		"before_field":    "harus sebelum %s",
+		"slug":            "hanya boleh berisi huruf kecil, angka dan tanda hubung tunggal",
+		"username":        "harus 3 sampai 32 huruf atau angka, boleh dipisahkan satu titik atau garis bawah",
+		"default":         "tidak valid",
+	},
+}
+
+// RegisterMessage adds or replaces the message template for tag in lang. Templates may contain
+// a single %s which is replaced with the tag parameter.
+func RegisterMessage(lang, tag, message string) {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+
+	if validationMessages[lang] == nil {
+		validationMessages[lang] = make(map[string]string)
+	}
+	validationMessages[lang][tag] = message
+}
+
+func messageFor(lang, tag string) string {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+
+	if msg, ok := validationMessages[lang][tag]; ok {
+		return msg
+	}
+	if msg, ok := validationMessages[DefaultLanguage][tag]; ok {
+		return msg
+	}
+	if msg, ok := validationMessages[lang]["default"]; ok {
+		return msg
+	}
+	return validationMessages[DefaultLanguage]["default"]
+}
+
+// LanguageFromHeader picks the supported language with the highest weight from an
+// Accept-Language header, falling back to DefaultLanguage.
+func LanguageFromHeader(header string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := strings.ToLower(strings.SplitN(strings.TrimSpace(fields[0]), "-", 2)[0])
+		if !supportedLanguage(lang) {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{lang: lang, q: q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return DefaultLanguage
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
+
+func supportedLanguage(lang string) bool {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+
+	_, ok := validationMessages[lang]
+	return ok
+}
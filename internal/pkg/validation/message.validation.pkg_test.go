@@ -0,0 +1,49 @@
+package validation
+
+import "testing"
+
+func TestLanguageFromHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", LanguageEnglish},
+		{"id", LanguageIndonesian},
+		{"ID", LanguageIndonesian},
+		{"id-ID,id;q=0.9,en;q=0.8", LanguageIndonesian},
+		{"en;q=0.5, id;q=0.8", LanguageIndonesian},
+		{"en-US,en;q=0.9,id;q=0.8", LanguageEnglish},
+		{"fr, de", LanguageEnglish},
+		{"fr;q=1, id;q=0.1", LanguageIndonesian},
+		{"id;q=0, en", LanguageEnglish},
+		{"id;q=abc", LanguageIndonesian},
+	}
+
+	for _, tt := range tests {
+		if got := LanguageFromHeader(tt.header); got != tt.want {
+			t.Errorf("LanguageFromHeader(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterMessage(t *testing.T) {
+	RegisterMessage("xx", "required", "needed")
+	t.Cleanup(func() {
+		messagesMu.Lock()
+		delete(validationMessages, "xx")
+		messagesMu.Unlock()
+	})
+
+	if got := messageFor("xx", "required"); got != "needed" {
+		t.Errorf("registered message = %q, want %q", got, "needed")
+	}
+	if got := messageFor("xx", "email"); got != "must be a valid email address" {
+		t.Errorf("missing tag = %q, want the English message", got)
+	}
+	if got := messageFor(LanguageIndonesian, "no_such_tag"); got != "tidak valid" {
+		t.Errorf("unknown tag = %q, want the Indonesian default", got)
+	}
+	if got := LanguageFromHeader("xx"); got != "xx" {
+		t.Errorf("LanguageFromHeader(xx) = %q, want the registered language", got)
+	}
+}
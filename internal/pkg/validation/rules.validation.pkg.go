@@ -0,0 +1,244 @@
+package validation
+
+import (
+	types "boilerplate-go/internal/common/type"
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// PasswordMinLength is the minimum length enforced by the strong_password rule.
+var PasswordMinLength = 8
+
+var (
+	phoneIDPattern  = regexp.MustCompile(`^(?:\+62|62|0)8[1-9][0-9]{6,11}$`)
+	nikPattern      = regexp.MustCompile(`^[0-9]{16}$`)
+	slugPattern     = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9](?:[._]?[a-zA-Z0-9])*$`)
+	sizePattern     = regexp.MustCompile(`^(?i)([0-9]+)\s*(B|KB|MB|GB)?$`)
+)
+
+var dateLayouts = []string{time.RFC3339, time.DateTime, time.DateOnly}
+
+// validatePhoneID accepts Indonesian mobile numbers in 08xx, 628xx or +628xx form. Spaces and
+// dashes are ignored.
+func validatePhoneID(fl validator.FieldLevel) bool {
+	value := strings.NewReplacer(" ", "", "-", "").Replace(fl.Field().String())
+	return phoneIDPattern.MatchString(value)
+}
+
+// validateNIK checks the structure of a 16 digit Indonesian national identity number: a
+// province code, and a birth date whose day is offset by 40 for women.
+func validateNIK(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if !nikPattern.MatchString(value) {
+		return false
+	}
+
+	province, _ := strconv.Atoi(value[0:2])
+	day, _ := strconv.Atoi(value[6:8])
+	month, _ := strconv.Atoi(value[8:10])
+	if day > 40 {
+		day -= 40
+	}
+
+	return province >= 11 && province <= 94 && day >= 1 && day <= 31 && month >= 1 && month <= 12
+}
+
+func validateStrongPassword(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if len([]rune(value)) < PasswordMinLength {
+		return false
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range value {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	return upper && lower && digit && symbol
+}
+
+func validateSlug(fl validator.FieldLevel) bool {
+	return slugPattern.MatchString(fl.Field().String())
+}
+
+// validateUsername accepts 3 to 32 letters and digits, with single dots or underscores between
+// them.
+func validateUsername(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	return len(value) >= 3 && len(value) <= 32 && usernamePattern.MatchString(value)
+}
+
+// validateFileMime checks the mime type of a BufferedFile or of every file in a slice against a
+// space separated list such as "image/png image/jpeg" or "image/*". The declared Content-Type is
+// chosen by the client, so the type sniffed from the content with http.DetectContentType must
+// match too. Formats it does not recognise sniff as a generic type, e.g. "application/zip" for
+// office documents, which then has to be allowed as well.
+func validateFileMime(fl validator.FieldLevel) bool {
+	files, ok := bufferedFiles(fl.Field())
+	if !ok {
+		return false
+	}
+
+	allowed := strings.Fields(fl.Param())
+	for _, file := range files {
+		sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(file.Buffer))
+		if !matchesMime(allowed, file.MimeType) || !matchesMime(allowed, sniffed) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesMime(allowed []string, mimeType string) bool {
+	for _, pattern := range allowed {
+		if ok, _ := path.Match(pattern, mimeType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validateFileSize checks that a BufferedFile, or every file in a slice, is not larger than the
+// parameter, e.g. "file_size=5MB".
+func validateFileSize(fl validator.FieldLevel) bool {
+	files, ok := bufferedFiles(fl.Field())
+	if !ok {
+		return false
+	}
+
+	limit, err := parseSize(fl.Param())
+	if err != nil {
+		panic(fmt.Sprintf("file_size: %v", err))
+	}
+
+	for _, file := range files {
+		if int64(file.Size) > limit {
+			return false
+		}
+	}
+	return true
+}
+
+func bufferedFiles(field reflect.Value) ([]types.BufferedFile, bool) {
+	switch value := field.Interface().(type) {
+	case types.BufferedFile:
+		return []types.BufferedFile{value}, true
+	case *types.BufferedFile:
+		if value == nil {
+			return nil, true
+		}
+		return []types.BufferedFile{*value}, true
+	case []types.BufferedFile:
+		return value, true
+	default:
+		return nil, false
+	}
+}
+
+func parseSize(value string) (int64, error) {
+	match := sizePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+
+	size, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", value, err)
+	}
+
+	switch strings.ToUpper(match[2]) {
+	case "KB":
+		size <<= 10
+	case "MB":
+		size <<= 20
+	case "GB":
+		size <<= 30
+	}
+	return size, nil
+}
+
+// validateAfterField checks that a date is after the field named by the parameter on the same
+// struct. Both fields may be time.Time, *time.Time or strings in RFC 3339, date-time or date
+// layout. Empty values pass so the rule can be combined with omitempty or required.
+func validateAfterField(fl validator.FieldLevel) bool {
+	return compareDateField(fl, func(value, other time.Time) bool { return value.After(other) })
+}
+
+// validateBeforeField is the counterpart of validateAfterField.
+func validateBeforeField(fl validator.FieldLevel) bool {
+	return compareDateField(fl, func(value, other time.Time) bool { return value.Before(other) })
+}
+
+func compareDateField(fl validator.FieldLevel, compare func(value, other time.Time) bool) bool {
+	value, ok, err := asTime(fl.Field())
+	if err != nil {
+		return false
+	}
+	if !ok {
+		return true
+	}
+
+	otherField, _, _, found := fl.GetStructFieldOKAdvanced2(fl.Parent(), fl.Param())
+	if !found {
+		return false
+	}
+
+	other, ok, err := asTime(otherField)
+	if err != nil || !ok {
+		return true
+	}
+	return compare(value, other)
+}
+
+func asTime(field reflect.Value) (time.Time, bool, error) {
+	for field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return time.Time{}, false, nil
+		}
+		field = field.Elem()
+	}
+
+	switch value := field.Interface().(type) {
+	case time.Time:
+		return value, !value.IsZero(), nil
+	case string:
+		if value == "" {
+			return time.Time{}, false, nil
+		}
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, true, nil
+			}
+		}
+		return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+	default:
+		return time.Time{}, false, fmt.Errorf("unsupported date type %T", value)
+	}
+}
+
+// withoutContext adapts a validator.Func to the context aware signature used by Rule.
+func withoutContext(fn validator.Func) validator.FuncCtx {
+	return func(_ context.Context, fl validator.FieldLevel) bool {
+		return fn(fl)
+	}
+}
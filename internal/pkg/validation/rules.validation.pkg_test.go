@@ -0,0 +1,284 @@
+package validation
+
+import (
+	types "boilerplate-go/internal/common/type"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ruleCase validates payload and expects failure of tag on field, or no failure when tag is empty.
+type ruleCase struct {
+	name    string
+	payload interface{}
+	field   string
+	tag     string
+}
+
+func runRuleCases(t *testing.T, cases []ruleCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tags := failedTags(t, tc.payload)
+			if tc.tag == "" {
+				if len(tags) != 0 {
+					t.Fatalf("failed %v, want valid", tags)
+				}
+				return
+			}
+			if tags[tc.field] != tc.tag {
+				t.Fatalf("failed %v, want %s on %s", tags, tc.tag, tc.field)
+			}
+		})
+	}
+}
+
+func TestPhoneID(t *testing.T) {
+	type payload struct {
+		Phone string `json:"phone" validate:"phone_id"`
+	}
+
+	tests := []struct {
+		phone string
+		valid bool
+	}{
+		{"081234567890", true},
+		{"6281234567890", true},
+		{"+6281234567890", true},
+		{"0812-3456-7890", true},
+		{"+62 812 3456 7890", true},
+		{"081234567", true},
+		{"08123456", false},
+		{"0812345", false},
+		{"0812345678901234", false},
+		{"08012345678", false},
+		{"0212345678", false},
+		{"+6221234567", false},
+		{"+1 812 3456 7890", false},
+		{"08123456789a", false},
+		{"", false},
+	}
+
+	var cases []ruleCase
+	for _, tt := range tests {
+		tc := ruleCase{name: tt.phone, payload: payload{Phone: tt.phone}, field: "phone"}
+		if !tt.valid {
+			tc.tag = "phone_id"
+		}
+		cases = append(cases, tc)
+	}
+	runRuleCases(t, cases)
+}
+
+func TestNIK(t *testing.T) {
+	type payload struct {
+		NIK string `json:"nik" validate:"nik"`
+	}
+
+	tests := []struct {
+		name  string
+		nik   string
+		valid bool
+	}{
+		{"male", "3201011508900001", true},
+		{"female day offset by 40", "3201015508900001", true},
+		{"last province", "9401010101000001", true},
+		{"province below range", "1001011508900001", false},
+		{"province above range", "9501011508900001", false},
+		{"day zero", "3201010008900001", false},
+		{"day between ranges", "3201013508900001", false},
+		{"female day above range", "3201017208900001", false},
+		{"month zero", "3201011500900001", false},
+		{"month thirteen", "3201011513900001", false},
+		{"15 digits", "320101150890001", false},
+		{"17 digits", "32010115089000011", false},
+		{"letters", "32010115089000AB", false},
+	}
+
+	var cases []ruleCase
+	for _, tt := range tests {
+		tc := ruleCase{name: tt.name, payload: payload{NIK: tt.nik}, field: "nik"}
+		if !tt.valid {
+			tc.tag = "nik"
+		}
+		cases = append(cases, tc)
+	}
+	runRuleCases(t, cases)
+}
+
+func TestStrongPassword(t *testing.T) {
+	type payload struct {
+		Password string `json:"password" validate:"strong_password"`
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"all classes", "Passw0rd!", true},
+		{"symbol instead of punctuation", "Passw0rd+", true},
+		{"multibyte letters", "Pässwörd1!", true},
+		{"no upper case", "passw0rd!", false},
+		{"no lower case", "PASSW0RD!", false},
+		{"no digit", "Password!", false},
+		{"no symbol", "Passw0rd1", false},
+		{"too short", "Pa0!", false},
+		{"seven runes", "Pässw0!", false},
+		{"empty", "", false},
+	}
+
+	var cases []ruleCase
+	for _, tt := range tests {
+		tc := ruleCase{name: tt.name, payload: payload{Password: tt.password}, field: "password"}
+		if !tt.valid {
+			tc.tag = "strong_password"
+		}
+		cases = append(cases, tc)
+	}
+	runRuleCases(t, cases)
+}
+
+func TestSlugAndUsername(t *testing.T) {
+	type slugPayload struct {
+		Slug string `json:"slug" validate:"slug"`
+	}
+	type usernamePayload struct {
+		Username string `json:"username" validate:"username"`
+	}
+
+	runRuleCases(t, []ruleCase{
+		{"slug", slugPayload{"hello-world-2"}, "slug", ""},
+		{"slug single word", slugPayload{"hello"}, "slug", ""},
+		{"slug upper case", slugPayload{"Hello-World"}, "slug", "slug"},
+		{"slug double hyphen", slugPayload{"hello--world"}, "slug", "slug"},
+		{"slug leading hyphen", slugPayload{"-hello"}, "slug", "slug"},
+		{"slug trailing hyphen", slugPayload{"hello-"}, "slug", "slug"},
+		{"slug space", slugPayload{"hello world"}, "slug", "slug"},
+		{"username", usernamePayload{"john.doe_99"}, "username", ""},
+		{"username minimum length", usernamePayload{"abc"}, "username", ""},
+		{"username maximum length", usernamePayload{strings.Repeat("a", 32)}, "username", ""},
+		{"username too short", usernamePayload{"ab"}, "username", "username"},
+		{"username too long", usernamePayload{strings.Repeat("a", 33)}, "username", "username"},
+		{"username double separator", usernamePayload{"john..doe"}, "username", "username"},
+		{"username leading separator", usernamePayload{"_john"}, "username", "username"},
+		{"username trailing separator", usernamePayload{"john."}, "username", "username"},
+		{"username hyphen", usernamePayload{"john-doe"}, "username", "username"},
+	})
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func bufferedFile(mimeType string, content []byte) types.BufferedFile {
+	return types.BufferedFile{
+		MediaType:    "image",
+		OriginalName: "upload",
+		Encoding:     "7bit",
+		MimeType:     mimeType,
+		Size:         len(content),
+		Buffer:       content,
+	}
+}
+
+func TestFileMime(t *testing.T) {
+	type payload struct {
+		File types.BufferedFile `json:"file" validate:"file_mime=image/*"`
+	}
+	type listPayload struct {
+		Files []types.BufferedFile `json:"files" validate:"file_mime=image/png image/jpeg"`
+	}
+	type pointerPayload struct {
+		File *types.BufferedFile `json:"file" validate:"omitempty,file_mime=image/png"`
+	}
+
+	png := bufferedFile("image/png", pngHeader)
+	runRuleCases(t, []ruleCase{
+		{"matching wildcard", payload{png}, "file", ""},
+		{"declared type spoofed", payload{bufferedFile("image/png", []byte("<?php echo 1; ?>"))}, "file", "file_mime"},
+		{"declared type not allowed", payload{bufferedFile("application/pdf", pngHeader)}, "file", "file_mime"},
+		{"every file in a slice", listPayload{[]types.BufferedFile{png, png}}, "files", ""},
+		{"one file in a slice spoofed", listPayload{[]types.BufferedFile{png, bufferedFile("image/jpeg", []byte("plain text"))}}, "files", "file_mime"},
+		{"pointer", pointerPayload{&png}, "file", ""},
+		{"nil pointer", pointerPayload{}, "file", ""},
+	})
+}
+
+func TestFileSize(t *testing.T) {
+	type payload struct {
+		File types.BufferedFile `json:"file" validate:"file_size=1KB"`
+	}
+
+	runRuleCases(t, []ruleCase{
+		{"below limit", payload{bufferedFile("image/png", bytes.Repeat([]byte{1}, 1000))}, "file", ""},
+		{"at limit", payload{bufferedFile("image/png", bytes.Repeat([]byte{1}, 1024))}, "file", ""},
+		{"above limit", payload{bufferedFile("image/png", bytes.Repeat([]byte{1}, 1025))}, "file", "file_size"},
+	})
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+		err   bool
+	}{
+		{"512", 512, false},
+		{"512B", 512, false},
+		{"1KB", 1 << 10, false},
+		{"5MB", 5 << 20, false},
+		{"5 mb", 5 << 20, false},
+		{" 2GB ", 2 << 30, false},
+		{"", 0, true},
+		{"MB", 0, true},
+		{"1.5MB", 0, true},
+		{"1TB", 0, true},
+		{"-1KB", 0, true},
+		{"99999999999999999999", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSize(tt.value)
+		if tt.err {
+			if err == nil {
+				t.Errorf("parseSize(%q) = %d, want error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseSize(%q) = %d, %v, want %d", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestDateFields(t *testing.T) {
+	type stringRange struct {
+		Start string `json:"start"`
+		End   string `json:"end" validate:"after_field=Start"`
+	}
+	type timeRange struct {
+		Start *time.Time `json:"start" validate:"omitempty,before_field=End"`
+		End   time.Time  `json:"end"`
+	}
+	type missingField struct {
+		End string `json:"end" validate:"after_field=Start"`
+	}
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	runRuleCases(t, []ruleCase{
+		{"after date", stringRange{"2026-01-01", "2026-01-02"}, "end", ""},
+		{"after RFC 3339", stringRange{"2026-01-01T10:00:00Z", "2026-01-01T11:00:00+00:00"}, "end", ""},
+		{"after date-time", stringRange{"2026-01-01 10:00:00", "2026-01-01 10:00:01"}, "end", ""},
+		{"equal", stringRange{"2026-01-01", "2026-01-01"}, "end", "after_field"},
+		{"before", stringRange{"2026-01-02", "2026-01-01"}, "end", "after_field"},
+		{"invalid date", stringRange{"2026-01-01", "tomorrow"}, "end", "after_field"},
+		{"empty value", stringRange{"2026-01-01", ""}, "end", ""},
+		{"empty other field", stringRange{"", "2026-01-01"}, "end", ""},
+		{"before time", timeRange{&now, later}, "start", ""},
+		{"not before time", timeRange{&later, now}, "start", "before_field"},
+		{"nil time", timeRange{nil, now}, "start", ""},
+		{"zero other time", timeRange{&now, time.Time{}}, "start", ""},
+		{"missing other field", missingField{"2026-01-01"}, "end", "after_field"},
+	})
+}
@@ -0,0 +1,37 @@
+package validation
+
+import "strings"
+
+// FieldError describes one failed rule. Message holds only the rule text, e.g. "is required";
+// clients pair it with Field themselves.
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors is returned by Validate and BindAndValidate when a payload fails validation.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	messages := make([]string, 0, len(v))
+	for _, e := range v {
+		messages = append(messages, e.Field+" "+e.Message)
+	}
+	return "Validation failed: " + strings.Join(messages, ", ")
+}
+
+// ValidationDetails lets apperror.From expose the field errors without importing this package.
+func (v ValidationErrors) ValidationDetails() any {
+	return []FieldError(v)
+}
+
+type Source int
+
+const (
+	SourceJSON Source = iota
+	SourceQuery
+	SourceURI
+	SourceForm
+)
@@ -0,0 +1,68 @@
+package validation
+
+import (
+	database "boilerplate-go/internal/pkg/db"
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var identifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// RegisterUnique enables the "db_unique=table.column" rule, which fails when a row with the
+// field value already exists. On updates, "db_unique=table.column:id" ignores the row whose id
+// column equals the sibling field tagged or named id; a zero id excludes nothing. The query runs
+// with the context passed to ValidateCtx or BindAndValidate, and a query failure is returned by
+// them instead of a validation error.
+func RegisterUnique(db *database.Database) error {
+	return Register(Rule{
+		Tag: "db_unique",
+		Func: func(ctx context.Context, fl validator.FieldLevel) bool {
+			spec, exclude, hasExclude := strings.Cut(fl.Param(), ":")
+			table, column, ok := strings.Cut(spec, ".")
+			if !ok || !identifierPattern.MatchString(table) || !identifierPattern.MatchString(column) ||
+				(hasExclude && !identifierPattern.MatchString(exclude)) {
+				panic(fmt.Sprintf("db_unique: invalid parameter %q, expected table.column or table.column:id", fl.Param()))
+			}
+
+			query := db.WithContext(ctx).
+				Table(table).
+				Where(fmt.Sprintf("%s = ?", column), fl.Field().Interface())
+			if hasExclude {
+				id := siblingField(fl.Parent(), exclude)
+				if !id.IsValid() {
+					panic(fmt.Sprintf("db_unique: no field %q next to %s", exclude, fl.FieldName()))
+				}
+				if !id.IsZero() {
+					query = query.Where(fmt.Sprintf("%s <> ?", exclude), id.Interface())
+				}
+			}
+
+			var count int64
+			if err := query.Count(&count).Error; err != nil {
+				return ReportError(ctx, fmt.Errorf("failed to check db_unique on %s: %w", spec, err))
+			}
+			return count == 0
+		},
+	})
+}
+
+// siblingField finds the field of parent whose json name or Go name matches name.
+func siblingField(parent reflect.Value, name string) reflect.Value {
+	parent = reflect.Indirect(parent)
+	if parent.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+
+	for i := 0; i < parent.NumField(); i++ {
+		field := parent.Type().Field(i)
+		if jsonTagName(field) == name || strings.EqualFold(field.Name, name) {
+			return reflect.Indirect(parent.Field(i))
+		}
+	}
+	return reflect.Value{}
+}
@@ -0,0 +1,161 @@
+package validation
+
+import (
+	database "boilerplate-go/internal/pkg/db"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeCount answers every query of the fake driver with a single count row, or with err.
+var fakeCount struct {
+	sync.Mutex
+	count   int64
+	err     error
+	queries []string
+	args    [][]driver.NamedValue
+}
+
+func setFakeCount(t *testing.T, count int64, err error) {
+	resetFakeCount(count, err)
+	t.Cleanup(func() { resetFakeCount(0, nil) })
+}
+
+func resetFakeCount(count int64, err error) {
+	fakeCount.Lock()
+	defer fakeCount.Unlock()
+	fakeCount.count, fakeCount.err, fakeCount.queries, fakeCount.args = count, err, nil, nil
+}
+
+func lastFakeQuery(t *testing.T) (string, []driver.NamedValue) {
+	t.Helper()
+	fakeCount.Lock()
+	defer fakeCount.Unlock()
+	if len(fakeCount.queries) == 0 {
+		t.Fatal("no query was run")
+	}
+	last := len(fakeCount.queries) - 1
+	return fakeCount.queries[last], fakeCount.args[last]
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	fakeCount.Lock()
+	defer fakeCount.Unlock()
+	fakeCount.queries = append(fakeCount.queries, query)
+	fakeCount.args = append(fakeCount.args, args)
+	if fakeCount.err != nil {
+		return nil, fakeCount.err
+	}
+	return &fakeRows{count: fakeCount.count}, nil
+}
+
+type fakeRows struct {
+	count int64
+	done  bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"count"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.count
+	return nil
+}
+
+func newFakeDatabase() *database.Database {
+	sql.Register("validation-fake", fakeDriver{})
+	conn, err := sql.Open("validation-fake", "")
+	if err != nil {
+		panic(err)
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		Logger:                 logger.Discard,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &database.Database{DB: db}
+}
+
+type uniqueCreate struct {
+	Email string `json:"email" validate:"db_unique=users.email"`
+}
+
+type uniqueUpdate struct {
+	ID    int    `json:"id"`
+	Email string `json:"email" validate:"db_unique=users.email:id"`
+}
+
+func TestDBUnique(t *testing.T) {
+	setFakeCount(t, 0, nil)
+	if tags := failedTags(t, uniqueCreate{Email: "free@example.com"}); tags != nil {
+		t.Errorf("free value failed: %v", tags)
+	}
+	query, args := lastFakeQuery(t)
+	if !strings.Contains(query, `FROM "users" WHERE email = $1`) {
+		t.Errorf("query = %q", query)
+	}
+	if len(args) != 1 || args[0].Value != "free@example.com" {
+		t.Errorf("args = %v", args)
+	}
+
+	setFakeCount(t, 1, nil)
+	if tags := failedTags(t, uniqueCreate{Email: "taken@example.com"}); tags["email"] != "db_unique" {
+		t.Errorf("taken value tags = %v, want db_unique on email", tags)
+	}
+}
+
+func TestDBUniqueExcludesCurrentRow(t *testing.T) {
+	setFakeCount(t, 0, nil)
+
+	failedTags(t, uniqueUpdate{ID: 7, Email: "me@example.com"})
+	query, args := lastFakeQuery(t)
+	if !strings.Contains(query, "email = $1 AND id <> $2") || len(args) != 2 || args[1].Value != int64(7) {
+		t.Errorf("query = %q, args = %v, want the row excluded by id", query, args)
+	}
+
+	failedTags(t, uniqueUpdate{Email: "me@example.com"})
+	query, _ = lastFakeQuery(t)
+	if strings.Contains(query, "<>") {
+		t.Errorf("query = %q, a zero id should exclude nothing", query)
+	}
+}
+
+func TestDBUniqueReportsQueryErrors(t *testing.T) {
+	queryErr := errors.New("connection reset")
+	setFakeCount(t, 0, queryErr)
+
+	err := ValidateCtx(context.Background(), uniqueCreate{Email: "a@example.com"}, LanguageEnglish)
+	if !errors.Is(err, queryErr) {
+		t.Fatalf("ValidateCtx = %v, want the query error", err)
+	}
+	var errs ValidationErrors
+	if errors.As(err, &errs) {
+		t.Fatalf("query error reported as a validation error: %v", errs)
+	}
+}
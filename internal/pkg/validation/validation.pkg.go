@@ -3,118 +3,200 @@ package validation
 import (
 	"boilerplate-go/internal/common/enum"
 	types "boilerplate-go/internal/common/type"
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin/binding"
 
 	"github.com/go-playground/validator/v10"
 )
 
-var val *validator.Validate
-
-var validationMessages = map[string]string{
-	"e164":         "must be a e164 formatted phone number",
-	"required":     "is required",
-	"url":          "must be a valid URL",
-	"datetime":     "must be a valid date-time format (2006-01-02T15:04:05Z07:00)",
-	"number":       "must be a number",
-	"oneof":        "must be one of the allowed values: %s",
-	"email":        "must be a valid email address",
-	"min":          "must be greater than or equal to %s",
-	"max":          "must be less than or equal to %s",
-	"len":          "must have the exact length of %s",
-	"alpha":        "must contain only alphabetic characters",
-	"alphanum":     "must contain only alphanumeric characters",
-	"eqfield":      "must be equal to the value of the %s field",
-	"nefield":      "must not be equal to the value of the %s field",
-	"gt":           "must be greater than %s",
-	"gte":          "must be greater than or equal to %s",
-	"lt":           "must be less than %s",
-	"lte":          "must be less than or equal to %s",
-	"excludes":     "must not contain the value %s",
-	"excludesall":  "must not contain any of the values: %s",
-	"enum":         "must be one of the allowed enum values: %s",
-	"stringToBool": "must be a boolean value",
+var (
+	val *validator.Validate
+
+	// rulesMu guards rules and configured. Validator engines are not safe to change while they
+	// validate, so rules can only be registered until Setup runs.
+	rulesMu    sync.Mutex
+	configured bool
+)
+
+// Rule is a custom validation tag with its message templates keyed by language.
+type Rule struct {
+	Tag      string
+	Func     validator.FuncCtx
+	Messages map[string]string
+}
+
+var rules = []Rule{
+	{Tag: "enum", Func: withoutContext(enum.ValidateEnum)},
+	{Tag: "stringToBool", Func: withoutContext(types.ValidateStringToBool)},
+	{Tag: "phone_id", Func: withoutContext(validatePhoneID)},
+	{Tag: "nik", Func: withoutContext(validateNIK)},
+	{Tag: "strong_password", Func: withoutContext(validateStrongPassword)},
+	{Tag: "file_mime", Func: withoutContext(validateFileMime)},
+	{Tag: "file_size", Func: withoutContext(validateFileSize)},
+	{Tag: "after_field", Func: withoutContext(validateAfterField)},
+	{Tag: "before_field", Func: withoutContext(validateBeforeField)},
+	{Tag: "slug", Func: withoutContext(validateSlug)},
+	{Tag: "username", Func: withoutContext(validateUsername)},
+}
+
+// Register adds a custom rule and its messages. It must be called before Setup, typically while
+// modules are wired at startup, and returns an error once Setup has configured the engines.
+func Register(rule Rule) error {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+
+	if configured {
+		return fmt.Errorf("failed to register %s validation: rules must be registered before Setup", rule.Tag)
+	}
+
+	for lang, message := range rule.Messages {
+		RegisterMessage(lang, rule.Tag, message)
+	}
+	rules = append(rules, rule)
+	return nil
 }
 
 func Setup() error {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+
 	val = validator.New(validator.WithRequiredStructEnabled())
 
 	if err := RegisterValidations(val); err != nil {
 		return fmt.Errorf("failed to register custom validations: %w", err)
 	}
 
-	val.RegisterTagNameFunc(func(fld reflect.StructField) string {
-		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
-		if name == "-" {
-			return ""
-		}
-		return name
-	})
+	val.RegisterTagNameFunc(jsonTagName)
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		if err := RegisterValidations(v); err != nil {
 			return fmt.Errorf("failed to register custom validations in Gin engine: %w", err)
 		}
+		v.RegisterTagNameFunc(jsonTagName)
 	} else {
 		return fmt.Errorf("failed to get validation engine")
 	}
 
+	configured = true
 	return nil
 }
 
 func RegisterValidations(v *validator.Validate) error {
-	if err := v.RegisterValidation("enum", enum.ValidateEnum); err != nil {
-		return fmt.Errorf("failed to register enum validation: %w", err)
-	}
-	if err := v.RegisterValidation("stringToBool", types.ValidateStringToBool); err != nil {
-		return fmt.Errorf("failed to register stringToBool validation: %w", err)
+	for _, rule := range rules {
+		if err := v.RegisterValidationCtx(rule.Tag, rule.Func); err != nil {
+			return fmt.Errorf("failed to register %s validation: %w", rule.Tag, err)
+		}
 	}
 	return nil
 }
 
+func jsonTagName(fld reflect.StructField) string {
+	name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// Validate validates payload and returns ValidationErrors with English messages.
 func Validate(payload interface{}) error {
-	if err := val.Struct(payload); err != nil {
-		var errorMessages []string
+	return ValidateLang(payload, DefaultLanguage)
+}
 
-		validationErrors := parsingErrorValidate(err)
-		if validationErrors != "" {
-			errorMessages = append(errorMessages, validationErrors)
-		}
-		message := "Validation failed: " + strings.Join(errorMessages, ", ")
-		return errors.New(message)
+// ValidateLang validates payload and returns ValidationErrors with messages in lang.
+func ValidateLang(payload interface{}, lang string) error {
+	return ValidateCtx(context.Background(), payload, lang)
+}
+
+// ValidateCtx is ValidateLang with a context for rules that query external systems. A failure
+// reported by such a rule through ReportError is returned as is, not as a validation error.
+func ValidateCtx(ctx context.Context, payload interface{}, lang string) error {
+	sink := &ruleErrors{}
+	err := val.StructCtx(context.WithValue(ctx, ruleErrorsKey{}, sink), payload)
+	if sinkErr := sink.get(); sinkErr != nil {
+		return sinkErr
+	}
+	if err != nil {
+		return Translate(err, lang)
 	}
 
 	return nil
 }
 
-func parsingErrorValidate(err error) string {
+type ruleErrorsKey struct{}
+
+type ruleErrors struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (r *ruleErrors) get() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// ReportError records a failure of the system a rule depends on, such as a database error, so
+// ValidateCtx returns it instead of blaming the field. Return its result from the rule: it is
+// true when the error was recorded, and false, failing the field, when the rule runs outside
+// ValidateCtx.
+func ReportError(ctx context.Context, err error) bool {
+	sink, ok := ctx.Value(ruleErrorsKey{}).(*ruleErrors)
+	if !ok {
+		return false
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.err == nil {
+		sink.err = err
+	}
+	return true
+}
+
+// Translate converts validator errors, including the ones returned by gin binding, into
+// ValidationErrors. Other errors are returned unchanged.
+func Translate(err error, lang string) error {
 	var errs validator.ValidationErrors
-	if errors.As(err, &errs) {
-		var sb strings.Builder
-		for _, e := range errs {
-			name := e.Namespace()
-			field := e.Field()
-			tag := e.Tag()
-			param := e.Param()
-			tp := e.Type()
-
-			msg := validationMessages[tag]
-			switch tag {
-			case "enum":
-				msg = fmt.Sprintf(msg, tp)
-			default:
-				if strings.Contains(msg, "%s") {
-					msg = fmt.Sprintf(msg, param)
-				}
+	if !errors.As(err, &errs) {
+		return err
+	}
+
+	result := make(ValidationErrors, 0, len(errs))
+	for _, e := range errs {
+		msg := messageFor(lang, e.Tag())
+		switch e.Tag() {
+		case "enum":
+			msg = fmt.Sprintf(msg, e.Type())
+		case "strong_password":
+			msg = fmt.Sprintf(msg, strconv.Itoa(PasswordMinLength))
+		default:
+			if strings.Contains(msg, "%s") {
+				msg = fmt.Sprintf(msg, e.Param())
 			}
-			sb.WriteString(fmt.Sprintf("%s: %s %s", name, field, msg))
-			sb.WriteString(", ")
 		}
-		return strings.TrimSuffix(sb.String(), ", ")
+
+		result = append(result, FieldError{
+			Field:   fieldPath(e.Namespace()),
+			Tag:     e.Tag(),
+			Param:   e.Param(),
+			Message: msg,
+		})
+	}
+	return result
+}
+
+// fieldPath strips the root struct name from a validator namespace ("User.address.city").
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
 	}
-	return err.Error()
+	return namespace
 }
@@ -0,0 +1,147 @@
+package validation
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// countedCalls counts every run of the "counted" rule, see TestBindAndValidateRunsRulesOnce.
+var countedCalls atomic.Int32
+
+func TestMain(m *testing.M) {
+	err := Register(Rule{
+		Tag: "counted",
+		Func: withoutContext(func(validator.FieldLevel) bool {
+			countedCalls.Add(1)
+			return true
+		}),
+		Messages: map[string]string{LanguageEnglish: "is counted"},
+	})
+	if err == nil {
+		err = RegisterUnique(newFakeDatabase())
+	}
+	if err == nil {
+		err = Setup()
+	}
+	if err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// failedTags validates payload and returns the tag of every failed rule keyed by field path.
+func failedTags(t *testing.T, payload interface{}) map[string]string {
+	t.Helper()
+
+	err := Validate(payload)
+	if err == nil {
+		return nil
+	}
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate returned %T: %v", err, err)
+	}
+
+	tags := make(map[string]string, len(errs))
+	for _, e := range errs {
+		tags[e.Field] = e.Tag
+	}
+	return tags
+}
+
+func TestRegisterAfterSetup(t *testing.T) {
+	err := Register(Rule{Tag: "late", Func: withoutContext(func(validator.FieldLevel) bool { return true })})
+	if err == nil {
+		t.Fatal("Register after Setup succeeded, want error")
+	}
+}
+
+type translatePayload struct {
+	Email    string `json:"email" validate:"required,email"`
+	Age      int    `json:"age" validate:"min=18"`
+	Password string `json:"password" validate:"strong_password"`
+	Address  struct {
+		City string `json:"city" validate:"required"`
+	} `json:"address"`
+}
+
+func TestTranslate(t *testing.T) {
+	payload := translatePayload{Age: 10, Password: "weak"}
+
+	tests := []struct {
+		lang     string
+		messages map[string]string
+	}{
+		{LanguageEnglish, map[string]string{
+			"email":        "is required",
+			"age":          "must be greater than or equal to 18",
+			"password":     "must be at least 8 characters long and contain upper and lower case letters, a number and a symbol",
+			"address.city": "is required",
+		}},
+		{LanguageIndonesian, map[string]string{
+			"email":        "wajib diisi",
+			"age":          "harus lebih besar dari atau sama dengan 18",
+			"password":     "minimal 8 karakter dan harus mengandung huruf besar, huruf kecil, angka dan simbol",
+			"address.city": "wajib diisi",
+		}},
+		// Unknown languages fall back to English.
+		{"fr", map[string]string{
+			"email":        "is required",
+			"age":          "must be greater than or equal to 18",
+			"password":     "must be at least 8 characters long and contain upper and lower case letters, a number and a symbol",
+			"address.city": "is required",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			var errs ValidationErrors
+			if err := ValidateLang(payload, tt.lang); !errors.As(err, &errs) {
+				t.Fatalf("ValidateLang returned %v, want ValidationErrors", err)
+			}
+			if len(errs) != len(tt.messages) {
+				t.Fatalf("got %d errors, want %d: %v", len(errs), len(tt.messages), errs)
+			}
+			for _, e := range errs {
+				if want := tt.messages[e.Field]; e.Message != want {
+					t.Errorf("%s message = %q, want %q", e.Field, e.Message, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTranslateFieldError(t *testing.T) {
+	var errs ValidationErrors
+	if err := Validate(translatePayload{Email: "not-an-email", Age: 18, Password: "Passw0rd!"}); !errors.As(err, &errs) {
+		t.Fatalf("Validate returned %v, want ValidationErrors", err)
+	}
+
+	want := []FieldError{
+		{Field: "email", Tag: "email", Message: "must be a valid email address"},
+		{Field: "address.city", Tag: "required", Message: "is required"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("errors = %v, want %v", errs, want)
+	}
+	for i := range want {
+		if errs[i] != want[i] {
+			t.Errorf("error %d = %+v, want %+v", i, errs[i], want[i])
+		}
+	}
+
+	if got, want := errs.Error(), "Validation failed: email must be a valid email address, address.city is required"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestTranslateKeepsOtherErrors(t *testing.T) {
+	err := errors.New("boom")
+	if got := Translate(err, LanguageEnglish); got != err {
+		t.Errorf("Translate = %v, want the error unchanged", got)
+	}
+}
@@ -2,17 +2,18 @@ package worker
 
 import (
 	"boilerplate-go/internal/pkg/logger"
+	"context"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
-func sampleSubscribeMessageRabbitHandler(msg *amqp.Delivery) (interface{}, error) {
-	logger.Debug.Println("Received message:", string(msg.Body))
+func sampleSubscribeMessageRabbitHandler(ctx context.Context, msg *amqp.Delivery) (interface{}, error) {
+	logger.FromContext(ctx).Debug("received message", "body", string(msg.Body))
 	return nil, nil
 }
 
-func sampleMessageRabbitRPCHandler(msg *amqp.Delivery) (interface{}, error) {
-	logger.Debug.Println("Received message:", string(msg.Body))
+func sampleMessageRabbitRPCHandler(ctx context.Context, msg *amqp.Delivery) (interface{}, error) {
+	logger.FromContext(ctx).Debug("received message", "body", string(msg.Body))
 	response := map[string]interface{}{
 		"key1": "value1",
 	}